@@ -0,0 +1,223 @@
+// Package canonical convert JSON documents in a canonical form, so that documents
+// written in Terraform configuration and documents returned by Elasticsearch API
+// can be stored and compared the same way.
+//
+// The canonical form:
+//  - has its keys sorted
+//  - has its numbers formatted without exponent and useless decimals
+//  - has not the fields added by Elasticsearch
+//  - has not the values equal to Elasticsearch defaults
+package canonical
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Rules are the API specific rules used to canonicalize JSON document
+// Paths are dotted paths from the document root, * match any key. Lists are traversed transparently.
+type Rules struct {
+	// Defaults are the values set by Elasticsearch when they are not provided.
+	// Fields equal to their default value are removed.
+	Defaults map[string]interface{}
+
+	// ServerFields are the fields added by Elasticsearch. They are always removed.
+	ServerFields []string
+}
+
+// Canonicalize return the canonical form of JSON document
+func Canonicalize(input string, rules *Rules) (string, error) {
+	value, err := decode(input)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(Normalize(value, rules))
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// Equal check if two JSON documents have the same canonical form
+// It return false if one of document is not valid JSON
+func Equal(a, b string, rules *Rules) bool {
+	va, err := decode(a)
+	if err != nil {
+		return false
+	}
+	vb, err := decode(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(Normalize(va, rules), Normalize(vb, rules))
+}
+
+// Normalize return the canonical form of decoded JSON value
+func Normalize(value interface{}, rules *Rules) interface{} {
+	value = normalizeNumbers(value)
+	if rules == nil {
+		return value
+	}
+
+	for _, path := range rules.ServerFields {
+		value = removePath(value, strings.Split(path, "."), nil)
+	}
+
+	// Deepest paths first, so that parent objects emptied by defaults removal can be removed too
+	paths := make([]string, 0, len(rules.Defaults))
+	for path := range rules.Defaults {
+		paths = append(paths, path)
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		di, dj := strings.Count(paths[i], "."), strings.Count(paths[j], ".")
+		if di != dj {
+			return di > dj
+		}
+		return paths[i] < paths[j]
+	})
+	for _, path := range paths {
+		defaultValue := normalizeNumbers(toJSONValue(rules.Defaults[path]))
+		value = removePath(value, strings.Split(path, "."), func(v interface{}) bool {
+			return reflect.DeepEqual(v, defaultValue)
+		})
+	}
+
+	return value
+}
+
+// decode decode JSON document and keep numbers as json.Number
+func decode(input string) (interface{}, error) {
+	var value interface{}
+	decoder := json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// toJSONValue convert Go value as decoded JSON value
+func toJSONValue(value interface{}) interface{} {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	result, err := decode(string(b))
+	if err != nil {
+		return value
+	}
+
+	return result
+}
+
+// maxNumberExponent is the highest exponent expanded by number normalization, numbers with higher exponent are kept as is
+const maxNumberExponent = 100
+
+// normalizeNumbers format all numbers without exponent and useless decimals
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			result[key] = normalizeNumbers(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, child := range v {
+			result = append(result, normalizeNumbers(child))
+		}
+		return result
+	case json.Number:
+		return normalizeNumber(v)
+	case float64:
+		return normalizeNumber(json.Number(strconv.FormatFloat(v, 'g', -1, 64)))
+	}
+
+	return value
+}
+
+// normalizeNumber format number without exponent and useless decimals
+// The number is rewritten from its decimal text, so that no precision is lost
+func normalizeNumber(number json.Number) json.Number {
+	text := string(number)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	exponent := 0
+	if i := strings.IndexAny(text, "eE"); i >= 0 {
+		e, err := strconv.Atoi(strings.TrimPrefix(text[i+1:], "+"))
+		if err != nil || e > maxNumberExponent || e < -maxNumberExponent {
+			return number
+		}
+		exponent = e
+		text = text[:i]
+	}
+
+	integerPart, fractionalPart := text, ""
+	if i := strings.Index(text, "."); i >= 0 {
+		integerPart, fractionalPart = text[:i], text[i+1:]
+	}
+	digits := integerPart + fractionalPart
+	point := len(integerPart) + exponent
+
+	trimmed := strings.TrimLeft(digits, "0")
+	point -= len(digits) - len(trimmed)
+	digits = strings.TrimRight(trimmed, "0")
+	if digits == "" {
+		return json.Number("0")
+	}
+
+	var result string
+	switch {
+	case point <= 0:
+		result = "0." + strings.Repeat("0", -point) + digits
+	case point >= len(digits):
+		result = digits + strings.Repeat("0", point-len(digits))
+	default:
+		result = digits[:point] + "." + digits[point:]
+	}
+	if negative {
+		result = "-" + result
+	}
+
+	return json.Number(result)
+}
+
+// removePath remove the field at path when match return true, or always if match is nil
+// Objects emptied by the removal are kept, they are removed only if there is a matching default
+func removePath(value interface{}, path []string, match func(interface{}) bool) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, child := range v {
+			result = append(result, removePath(child, path, match))
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if key != path[0] && path[0] != "*" {
+				result[key] = child
+				continue
+			}
+			if len(path) > 1 {
+				result[key] = removePath(child, path[1:], match)
+				continue
+			}
+			if match != nil && !match(child) {
+				result[key] = child
+			}
+		}
+		return result
+	}
+
+	return value
+}
@@ -0,0 +1,136 @@
+package canonical
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	cases := []struct {
+		name     string
+		input    string
+		rules    *Rules
+		expected string
+	}{
+		{
+			name:     "key ordering",
+			input:    `{"b": 1, "a": {"d": true, "c": null}}`,
+			expected: `{"a":{"c":null,"d":true},"b":1}`,
+		},
+		{
+			name:     "number formats",
+			input:    `{"a": 1.0, "b": 1e3, "c": 0.50, "d": 12345678901234567890, "e": [2.50, -0.0], "f": 1.5E-3, "g": -12345678901234567890.000}`,
+			expected: `{"a":1,"b":1000,"c":0.5,"d":12345678901234567890,"e":[2.5,0],"f":0.0015,"g":-12345678901234567890}`,
+		},
+		{
+			name:  "server fields",
+			input: `{"version": 2, "modified_date": "2020-01-01", "policy": {"phases": {"hot": {"actions": {}}}}}`,
+			rules: &Rules{
+				ServerFields: []string{"version", "modified_date"},
+			},
+			expected: `{"policy":{"phases":{"hot":{"actions":{}}}}}`,
+		},
+		{
+			name:  "defaults with wildcard",
+			input: `{"phases": {"hot": {"min_age": "0ms"}, "delete": {"min_age": "30d"}}}`,
+			rules: &Rules{
+				Defaults: map[string]interface{}{
+					"phases.*.min_age": "0ms",
+				},
+			},
+			expected: `{"phases":{"delete":{"min_age":"30d"},"hot":{}}}`,
+		},
+		{
+			name:  "defaults emptied by other defaults",
+			input: `{"order": 0, "settings": {"index": {"hidden": false}}, "mappings": {}}`,
+			rules: &Rules{
+				Defaults: map[string]interface{}{
+					"order":                 0,
+					"settings":              map[string]interface{}{},
+					"settings.index":        map[string]interface{}{},
+					"settings.index.hidden": false,
+					"mappings":              map[string]interface{}{},
+				},
+			},
+			expected: `{}`,
+		},
+		{
+			name:  "defaults in list",
+			input: `{"processors": [{"set": {"field": "a", "override": true}}, {"set": {"field": "b", "override": false}}]}`,
+			rules: &Rules{
+				Defaults: map[string]interface{}{
+					"processors.set.override": true,
+				},
+			},
+			expected: `{"processors":[{"set":{"field":"a"}},{"set":{"field":"b","override":false}}]}`,
+		},
+	}
+
+	for _, c := range cases {
+		result, err := Canonicalize(c.input, c.rules)
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if result != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, result)
+		}
+	}
+}
+
+func TestCanonicalizeInvalidJSON(t *testing.T) {
+	if _, err := Canonicalize(`{"a": `, nil); err == nil {
+		t.Error("Invalid JSON must return error")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	cases := []struct {
+		name  string
+		a     string
+		b     string
+		rules *Rules
+		equal bool
+	}{
+		{
+			name:  "same document with other format",
+			a:     `{"a": 1, "b": [1, 2]}`,
+			b:     "{\n  \"b\": [1.0, 2],\n  \"a\": 1e0\n}",
+			equal: true,
+		},
+		{
+			name: "default value missing",
+			a:    `{"order": 0, "index_patterns": ["test*"]}`,
+			b:    `{"index_patterns": ["test*"]}`,
+			rules: &Rules{
+				Defaults: map[string]interface{}{
+					"order": 0,
+				},
+			},
+			equal: true,
+		},
+		{
+			name:  "list order matters",
+			a:     `{"a": [1, 2]}`,
+			b:     `{"a": [2, 1]}`,
+			equal: false,
+		},
+		{
+			name:  "string is not number",
+			a:     `{"a": "1"}`,
+			b:     `{"a": 1}`,
+			equal: false,
+		},
+		{
+			name:  "invalid JSON",
+			a:     `{"a": 1}`,
+			b:     `{"a": `,
+			equal: false,
+		},
+	}
+
+	for _, c := range cases {
+		if equal := Equal(c.a, c.b, c.rules); equal != c.equal {
+			t.Errorf("%s: expected equal to be %t", c.name, c.equal)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Read index lifecycle state of indices in Elasticsearch
+// API documentation: https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-explain-lifecycle.html
+// Supported version:
+//  - v7
+
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// IndexLifecycleExplain object returned by API
+type IndexLifecycleExplain struct {
+	Indices map[string]*IndexLifecycleExplainIndex `json:"indices"`
+}
+
+// IndexLifecycleExplainIndex is the lifecycle state of one index
+type IndexLifecycleExplainIndex struct {
+	Index                string      `json:"index"`
+	Managed              bool        `json:"managed"`
+	Policy               string      `json:"policy,omitempty"`
+	Phase                string      `json:"phase,omitempty"`
+	Action               string      `json:"action,omitempty"`
+	Step                 string      `json:"step,omitempty"`
+	FailedStep           string      `json:"failed_step,omitempty"`
+	FailedStepRetryCount int         `json:"failed_step_retry_count,omitempty"`
+	StepInfo             interface{} `json:"step_info,omitempty"`
+}
+
+// dataSourceElasticsearchIndexLifecycleExplain handle the ILM explain API call
+func dataSourceElasticsearchIndexLifecycleExplain() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceElasticsearchIndexLifecycleExplainRead,
+
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Index name or pattern",
+			},
+			"only_errors": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"only_managed": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"indices": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"managed": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"policy": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"phase": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"action": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"step": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"failed_step": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"failed_step_retry_count": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"step_info": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceElasticsearchIndexLifecycleExplainRead read the lifecycle state of indices
+func dataSourceElasticsearchIndexLifecycleExplainRead(d *schema.ResourceData, meta interface{}) error {
+	index := d.Get("index").(string)
+	onlyErrors := d.Get("only_errors").(bool)
+	onlyManaged := d.Get("only_managed").(bool)
+
+	explain, err := explainIndexLifecycle(index, onlyErrors, onlyManaged, meta.(*providerConf).client)
+	if err != nil {
+		return err
+	}
+	if explain == nil {
+		return errors.Errorf("Index %s not found", index)
+	}
+
+	d.SetId(index)
+	d.Set("indices", flattenIndexLifecycleExplain(explain))
+
+	return nil
+}
+
+// explainIndexLifecycle call the ILM explain API on index or pattern
+// It return nil if the index not exist
+func explainIndexLifecycle(index string, onlyErrors bool, onlyManaged bool, client *elastic.Client) (*IndexLifecycleExplain, error) {
+	res, err := client.API.ILM.ExplainLifecycle(
+		index,
+		client.API.ILM.ExplainLifecycle.WithContext(context.Background()),
+		client.API.ILM.ExplainLifecycle.WithPretty(),
+		client.API.ILM.ExplainLifecycle.WithOnlyErrors(onlyErrors),
+		client.API.ILM.ExplainLifecycle.WithOnlyManaged(onlyManaged),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return nil, nil
+		}
+		return nil, errors.Errorf("Error when explain lifecycle of %s: %s", index, res.String())
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Explain lifecycle of %s successfully:\n%s", index, string(b))
+
+	explain := &IndexLifecycleExplain{}
+	err = json.Unmarshal(b, explain)
+	if err != nil {
+		return nil, err
+	}
+
+	return explain, nil
+}
+
+// flattenIndexLifecycleExplain convert IndexLifecycleExplain object to indices list sorted by index name
+func flattenIndexLifecycleExplain(explain *IndexLifecycleExplain) []interface{} {
+	names := make([]string, 0, len(explain.Indices))
+	for name := range explain.Indices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	indices := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		explainIndex := explain.Indices[name]
+
+		indices = append(indices, map[string]interface{}{
+			"index":                   name,
+			"managed":                 explainIndex.Managed,
+			"policy":                  explainIndex.Policy,
+			"phase":                   explainIndex.Phase,
+			"action":                  explainIndex.Action,
+			"step":                    explainIndex.Step,
+			"failed_step":             explainIndex.FailedStep,
+			"failed_step_retry_count": explainIndex.FailedStepRetryCount,
+			"step_info":               flattenIndexLifecycleExplainStepInfo(explainIndex.StepInfo),
+		})
+	}
+
+	return indices
+}
+
+// flattenIndexLifecycleExplainStepInfo convert step info as JSON string
+func flattenIndexLifecycleExplainStepInfo(stepInfo interface{}) string {
+	if stepInfo == nil {
+		return ""
+	}
+	b, _ := json.Marshal(stepInfo)
+	return string(b)
+}
@@ -0,0 +1,33 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccElasticsearchIndexLifecycleExplainDataSource(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIndexLifecycleExplainDataSource,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_index_lifecycle_explain.test", "id", "*"),
+					resource.TestCheckResourceAttrSet("data.elasticsearch_index_lifecycle_explain.test", "indices.#"),
+				),
+			},
+		},
+	})
+}
+
+var testElasticsearchIndexLifecycleExplainDataSource = `
+data "elasticsearch_index_lifecycle_explain" "test" {
+  index        = "*"
+  only_managed = true
+}
+`
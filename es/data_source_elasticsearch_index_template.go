@@ -0,0 +1,349 @@
+// Read legacy or composable index template in Elasticsearch
+// API documentation:
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-templates-v1.html
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-get-template.html
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-simulate-template.html
+// Supported version:
+//  - v7
+
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+
+	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	indexTemplateTypeLegacy     = "legacy"
+	indexTemplateTypeComposable = "composable"
+)
+
+// dataSourceElasticsearchIndexTemplate handle the get index template API call for legacy and composable template
+func dataSourceElasticsearchIndexTemplate() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceElasticsearchIndexTemplateRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"name", "index_pattern"},
+			},
+			"index_pattern": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Read the template with the highest priority matching this index name or pattern",
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Description:  "The template type, legacy or composable. Composable template are looked up first if not set",
+				ValidateFunc: validation.StringInSlice([]string{indexTemplateTypeLegacy, indexTemplateTypeComposable}, false),
+			},
+			"index_patterns": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"composed_of": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"priority": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The priority of composable template or the order of legacy template",
+			},
+			"version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"body": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The normalized JSON template as returned by the API",
+			},
+			"settings": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The effective settings, flattened with dot keys",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"mappings": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The effective mappings JSON",
+			},
+			"mapping_fields": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "The type of each mapping field, keyed by field path",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"aliases": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The effective aliases JSON",
+			},
+		},
+	}
+}
+
+// dataSourceElasticsearchIndexTemplateRead read the composable or legacy template
+func dataSourceElasticsearchIndexTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	index := d.Get("index_pattern").(string)
+	templateType := d.Get("type").(string)
+	client := meta.(*providerConf).client
+
+	if templateType != indexTemplateTypeLegacy {
+		found, err := readComposableIndexTemplateDataSource(d, name, index, client)
+		if err != nil {
+			return err
+		}
+		if found {
+			return nil
+		}
+	}
+
+	if templateType != indexTemplateTypeComposable {
+		found, err := readIndexTemplateLegacyDataSource(d, name, index, client)
+		if err != nil {
+			return err
+		}
+		if found {
+			return nil
+		}
+	}
+
+	if name != "" {
+		return errors.Errorf("Index template %s not found", name)
+	}
+	return errors.Errorf("No index template match %s", index)
+}
+
+// readComposableIndexTemplateDataSource set the data source from composable template
+// The settings, mappings and aliases include the component templates
+func readComposableIndexTemplateDataSource(d *schema.ResourceData, name string, index string, client *elastic.Client) (bool, error) {
+	var template *ComposableIndexTemplateSpec
+	var err error
+	if name != "" {
+		template, err = getComposableIndexTemplate(name, client)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		templates, err := getComposableIndexTemplates("", client)
+		if err != nil {
+			return false, err
+		}
+		name = resolveComposableIndexTemplate(index, templates)
+		for _, t := range templates {
+			if t.Name == name {
+				template = t.IndexTemplate
+			}
+		}
+	}
+	if template == nil {
+		return false, nil
+	}
+
+	simulation, err := simulateComposableIndexTemplateByName(name, client)
+	if err != nil {
+		return false, err
+	}
+
+	body, err := canonical.Canonicalize(convertInterfaceToJSONString(template), nil)
+	if err != nil {
+		return false, err
+	}
+
+	d.SetId(name)
+	d.Set("name", name)
+	d.Set("type", indexTemplateTypeComposable)
+	d.Set("index_patterns", template.IndexPatterns)
+	d.Set("composed_of", template.ComposedOf)
+	d.Set("priority", template.Priority)
+	d.Set("version", template.Version)
+	d.Set("body", body)
+
+	return true, setIndexTemplateDataSourceTemplate(d, simulation.Template.Settings, simulation.Template.Mappings, simulation.Template.Aliases)
+}
+
+// readIndexTemplateLegacyDataSource set the data source from legacy template
+func readIndexTemplateLegacyDataSource(d *schema.ResourceData, name string, index string, client *elastic.Client) (bool, error) {
+	templates, err := getIndexTemplatesLegacy(name, client)
+	if err != nil {
+		return false, err
+	}
+	if name == "" {
+		name = resolveIndexTemplateLegacy(index, templates)
+	}
+	template, ok := templates[name]
+	if !ok || template == nil {
+		return false, nil
+	}
+
+	body, err := canonical.Canonicalize(convertInterfaceToJSONString(template), indexTemplateJSONRules)
+	if err != nil {
+		return false, err
+	}
+
+	d.SetId(name)
+	d.Set("name", name)
+	d.Set("type", indexTemplateTypeLegacy)
+	d.Set("index_patterns", template.IndexPatterns)
+	d.Set("composed_of", []string{})
+	d.Set("priority", template.Order)
+	d.Set("version", template.Version)
+	d.Set("body", body)
+
+	return true, setIndexTemplateDataSourceTemplate(d, template.Settings, template.Mappings, template.Aliases)
+}
+
+// setIndexTemplateDataSourceTemplate set the settings, mappings and aliases attributes
+func setIndexTemplateDataSourceTemplate(d *schema.ResourceData, settings interface{}, mappings interface{}, aliases interface{}) error {
+	flattenSettings := make(map[string]interface{})
+	if m, ok := settings.(map[string]interface{}); ok {
+		for k, v := range normalizeIndexSettings(m) {
+			if s, ok := v.(string); ok {
+				flattenSettings[k] = s
+			} else {
+				flattenSettings[k] = convertInterfaceToJSONString(v)
+			}
+		}
+	}
+
+	mappingFields := make(map[string]interface{})
+	if m, ok := mappings.(map[string]interface{}); ok {
+		flattenIndexMappingFields("", m, mappingFields)
+	}
+
+	if err := d.Set("settings", flattenSettings); err != nil {
+		return err
+	}
+	if err := d.Set("mappings", convertInterfaceToJSONString(mappings)); err != nil {
+		return err
+	}
+	if err := d.Set("mapping_fields", mappingFields); err != nil {
+		return err
+	}
+	return d.Set("aliases", convertInterfaceToJSONString(aliases))
+}
+
+// simulateComposableIndexTemplateByName return the configuration of existing composable template
+// merged with its component templates
+func simulateComposableIndexTemplateByName(name string, client *elastic.Client) (*ComposableIndexTemplateSimulation, error) {
+	res, err := client.API.Indices.SimulateTemplate(
+		client.API.Indices.SimulateTemplate.WithName(name),
+		client.API.Indices.SimulateTemplate.WithContext(context.Background()),
+		client.API.Indices.SimulateTemplate.WithPretty(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, errors.Errorf("Error when simulate composable index template %s: %s", name, res.String())
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	simulation := &ComposableIndexTemplateSimulation{}
+	if err := json.Unmarshal(b, simulation); err != nil {
+		return nil, err
+	}
+	if simulation.Template == nil {
+		simulation.Template = &ComposableIndexTemplateTemplate{}
+	}
+
+	log.Debugf("Simulate composable index template %s: %s", name, string(b))
+
+	return simulation, nil
+}
+
+// resolveIndexTemplateLegacy return the name of legacy template with the highest order matching index
+// Templates with the same order are sorted by name to keep the result stable
+func resolveIndexTemplateLegacy(index string, templates IndexTemplateLegacy) string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resolved := ""
+	for _, name := range names {
+		template := templates[name]
+		if template == nil || (resolved != "" && template.Order <= templates[resolved].Order) {
+			continue
+		}
+		for _, pattern := range template.IndexPatterns {
+			if indexPatternsOverlap(pattern, index) {
+				resolved = name
+				break
+			}
+		}
+	}
+
+	return resolved
+}
+
+// flattenIndexMappingFields set the type of each field in result, keyed by field path
+// Multi-fields are keyed by field path followed by the sub field name
+func flattenIndexMappingFields(prefix string, mapping map[string]interface{}, result map[string]interface{}) {
+	properties, ok := mapping["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for name, raw := range properties {
+		field, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		if fieldType, ok := field["type"].(string); ok {
+			result[path] = fieldType
+		} else if _, ok := field["properties"]; ok {
+			result[path] = "object"
+		}
+
+		if fields, ok := field["fields"].(map[string]interface{}); ok {
+			for subName, rawSubField := range fields {
+				if subField, ok := rawSubField.(map[string]interface{}); ok {
+					if subType, ok := subField["type"].(string); ok {
+						result[path+"."+subName] = subType
+					}
+				}
+			}
+		}
+
+		flattenIndexMappingFields(path, field, result)
+	}
+}
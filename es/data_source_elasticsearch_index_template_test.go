@@ -0,0 +1,151 @@
+package es
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccElasticsearchIndexTemplateDataSource(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIndexTemplateDataSource,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_index_template.composable", "type", "composable"),
+					resource.TestCheckResourceAttr("data.elasticsearch_index_template.composable", "priority", "20"),
+					resource.TestCheckResourceAttr("data.elasticsearch_index_template.composable", "settings.index.number_of_replicas", "0"),
+					resource.TestCheckResourceAttr("data.elasticsearch_index_template.composable", "mapping_fields.name", "keyword"),
+					resource.TestCheckResourceAttr("data.elasticsearch_index_template.pattern", "name", "terraform-test-composable"),
+					resource.TestCheckResourceAttr("data.elasticsearch_index_template.legacy", "type", "legacy"),
+					resource.TestCheckResourceAttr("data.elasticsearch_index_template.legacy", "mapping_fields.message", "text"),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenIndexMappingFields(t *testing.T) {
+	mappings := map[string]interface{}{
+		"properties": map[string]interface{}{
+			"name": map[string]interface{}{
+				"type": "text",
+				"fields": map[string]interface{}{
+					"raw": map[string]interface{}{
+						"type": "keyword",
+					},
+				},
+			},
+			"user": map[string]interface{}{
+				"properties": map[string]interface{}{
+					"id": map[string]interface{}{
+						"type": "long",
+					},
+				},
+			},
+		},
+	}
+
+	expected := map[string]interface{}{
+		"name":     "text",
+		"name.raw": "keyword",
+		"user":     "object",
+		"user.id":  "long",
+	}
+
+	actual := make(map[string]interface{})
+	flattenIndexMappingFields("", mappings, actual)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestResolveIndexTemplateLegacy(t *testing.T) {
+	templates := IndexTemplateLegacy{
+		"logs": &IndexTemplateLegacySpec{
+			IndexPatterns: []string{"logs-*"},
+			Order:         1,
+		},
+		"logs-app": &IndexTemplateLegacySpec{
+			IndexPatterns: []string{"logs-app-*"},
+			Order:         2,
+		},
+		"metrics": &IndexTemplateLegacySpec{
+			IndexPatterns: []string{"metrics-*"},
+			Order:         3,
+		},
+	}
+
+	cases := []struct {
+		index    string
+		expected string
+	}{
+		{"logs-app-2021", "logs-app"},
+		{"logs-web-2021", "logs"},
+		{"traces-2021", ""},
+	}
+
+	for _, tc := range cases {
+		if actual := resolveIndexTemplateLegacy(tc.index, templates); actual != tc.expected {
+			t.Errorf("Expected %q for %s, got %q", tc.expected, tc.index, actual)
+		}
+	}
+}
+
+var testElasticsearchIndexTemplateDataSource = `
+resource "elasticsearch_composable_index_template" "test" {
+  name           = "terraform-test-composable"
+  index_patterns = ["test-composable-*"]
+  priority       = 20
+
+  template {
+    settings = jsonencode({
+      number_of_replicas = 0
+    })
+    mappings = jsonencode({
+      properties = {
+        name = {
+          type = "keyword"
+        }
+      }
+    })
+  }
+}
+
+resource "elasticsearch_index_template" "test" {
+  name     = "terraform-test-legacy"
+  template = <<EOF
+{
+  "index_patterns": ["test-legacy-*"],
+  "mappings": {
+    "properties": {
+      "message": {
+        "type": "text"
+      }
+    }
+  }
+}
+EOF
+}
+
+data "elasticsearch_index_template" "composable" {
+  name = elasticsearch_composable_index_template.test.id
+}
+
+data "elasticsearch_index_template" "pattern" {
+  index_pattern = "test-composable-2021"
+
+  depends_on = [elasticsearch_composable_index_template.test]
+}
+
+data "elasticsearch_index_template" "legacy" {
+  name = elasticsearch_index_template.test.id
+  type = "legacy"
+}
+`
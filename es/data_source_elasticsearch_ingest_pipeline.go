@@ -0,0 +1,107 @@
+// Read ingest pipeline in Elasticsearch
+// API documentation:
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/get-pipeline-api.html
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/simulate-pipeline-api.html
+// Supported version:
+//  - v7
+
+package es
+
+import (
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+)
+
+// dataSourceElasticsearchIngestPipeline handle the get pipeline API call and optionally simulate it on documents
+func dataSourceElasticsearchIngestPipeline() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceElasticsearchIngestPipelineRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"simulate": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Documents source to process with the pipeline",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsJSON,
+				},
+			},
+			"body": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"description": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"simulate_output": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Documents source processed by the pipeline, in the same order as simulate",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// dataSourceElasticsearchIngestPipelineRead read the pipeline and simulate it if needed
+func dataSourceElasticsearchIngestPipelineRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	client := meta.(*providerConf).client
+
+	pipeline, err := getIngestPipeline(name, client)
+	if err != nil {
+		return err
+	}
+	if pipeline == nil {
+		return errors.Errorf("Ingest pipeline %s not found", name)
+	}
+
+	// Same body as the resource, without the ownership marker
+	body, err := canonical.Canonicalize(convertInterfaceToJSONString(pipeline), ingestPipelineJSONRules)
+	if err != nil {
+		return err
+	}
+
+	description, _ := pipeline["description"].(string)
+	version, _ := pipeline["version"].(float64)
+
+	outputs := make([]interface{}, 0)
+	if documents := d.Get("simulate").([]interface{}); len(documents) > 0 {
+		result, err := simulateIngestPipeline(body, documents, client)
+		if err != nil {
+			return err
+		}
+		for i, doc := range result.Docs {
+			if doc.Error != nil {
+				return errors.Errorf("Simulate ingest pipeline %s failed on simulate.%d: %s: %s", name, i, doc.Error["type"], doc.Error["reason"])
+			}
+			var source interface{}
+			if doc.Doc != nil {
+				source = doc.Doc.Source
+			}
+			outputs = append(outputs, convertInterfaceToJSONString(source))
+		}
+	}
+
+	d.SetId(name)
+	d.Set("body", body)
+	d.Set("description", description)
+	d.Set("version", int(version))
+	d.Set("simulate_output", outputs)
+
+	return nil
+}
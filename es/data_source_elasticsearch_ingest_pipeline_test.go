@@ -0,0 +1,57 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccElasticsearchIngestPipelineDataSource(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIngestPipelineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIngestPipelineDataSource,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_ingest_pipeline.test", "version", "123"),
+					resource.TestCheckResourceAttr("data.elasticsearch_ingest_pipeline.test", "description", "describe pipeline"),
+					resource.TestCheckResourceAttr("data.elasticsearch_ingest_pipeline.test", "simulate_output.0", `{"foo":"bar","message":"test"}`),
+					resource.TestCheckResourceAttrPair("data.elasticsearch_ingest_pipeline.test", "body", "elasticsearch_ingest_pipeline.test", "body"),
+				),
+			},
+		},
+	})
+}
+
+var testElasticsearchIngestPipelineDataSource = `
+resource "elasticsearch_ingest_pipeline" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "description" : "describe pipeline",
+  "version": 123,
+  "processors" : [
+    {
+      "set" : {
+        "field": "foo",
+        "value": "bar"
+      }
+    }
+  ]
+}
+EOF
+}
+
+data "elasticsearch_ingest_pipeline" "test" {
+  name = elasticsearch_ingest_pipeline.test.id
+
+  simulate = [
+    jsonencode({ message = "test" })
+  ]
+}
+`
@@ -0,0 +1,151 @@
+// Read the status of watch in Elasticsearch
+// API documentation: https://www.elastic.co/guide/en/elasticsearch/reference/current/watcher-api-get-watch.html
+// Supported version:
+//  - v7
+
+package es
+
+import (
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// dataSourceElasticsearchWatchStatus handle the get watch API call to read watch status
+func dataSourceElasticsearchWatchStatus() *schema.Resource {
+	return &schema.Resource{
+		Read: dataSourceElasticsearchWatchStatusRead,
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"last_checked": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"last_met_condition": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"execution_state": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"version": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"actions": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ack_state": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ack_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_execution_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_execution_successful": {
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"last_execution_reason": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_successful_execution_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"last_throttle_timestamp": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// dataSourceElasticsearchWatchStatusRead read the watch status
+func dataSourceElasticsearchWatchStatusRead(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	watcher, err := getWatcher(name, meta.(*providerConf).client)
+	if err != nil {
+		return err
+	}
+	if watcher == nil {
+		return errors.Errorf("Watcher %s not found", name)
+	}
+
+	status := watcher.Status
+	if status == nil {
+		status = &WatcherStatus{}
+	}
+
+	d.SetId(name)
+	d.Set("active", status.State != nil && status.State.Active)
+	d.Set("last_checked", status.LastChecked)
+	d.Set("last_met_condition", status.LastMetCondition)
+	d.Set("execution_state", status.ExecutionState)
+	d.Set("version", status.Version)
+	d.Set("actions", flattenWatcherActionsStatus(status.Actions))
+
+	return nil
+}
+
+// flattenWatcherActionsStatus convert actions status to list sorted by action name
+func flattenWatcherActionsStatus(actionsStatus map[string]*WatcherActionStatus) []interface{} {
+	names := make([]string, 0, len(actionsStatus))
+	for name := range actionsStatus {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	actions := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		actionStatus := actionsStatus[name]
+		action := map[string]interface{}{
+			"name": name,
+		}
+		if actionStatus.Ack != nil {
+			action["ack_state"] = actionStatus.Ack.State
+			action["ack_timestamp"] = actionStatus.Ack.Timestamp
+		}
+		if actionStatus.LastExecution != nil {
+			action["last_execution_timestamp"] = actionStatus.LastExecution.Timestamp
+			action["last_execution_successful"] = actionStatus.LastExecution.Successful
+			action["last_execution_reason"] = actionStatus.LastExecution.Reason
+		}
+		if actionStatus.LastSuccessfulExecution != nil {
+			action["last_successful_execution_timestamp"] = actionStatus.LastSuccessfulExecution.Timestamp
+		}
+		if actionStatus.LastThrottle != nil {
+			action["last_throttle_timestamp"] = actionStatus.LastThrottle.Timestamp
+		}
+
+		actions = append(actions, action)
+	}
+
+	return actions
+}
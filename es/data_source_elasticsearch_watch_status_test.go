@@ -0,0 +1,48 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccElasticsearchWatchStatusDataSource(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchWatcherDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchWatchStatusDataSource,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_watch_status.test", "active", "true"),
+					resource.TestCheckResourceAttr("data.elasticsearch_watch_status.test", "actions.0.name", "log_error"),
+				),
+			},
+		},
+	})
+}
+
+var testElasticsearchWatchStatusDataSource = `
+resource "elasticsearch_watcher" "test" {
+  name = "terraform-test"
+
+  schedule {
+    interval = "10m"
+  }
+
+  action {
+    name = "log_error"
+    logging {
+      text = "test"
+    }
+  }
+}
+
+data "elasticsearch_watch_status" "test" {
+  name = elasticsearch_watcher.test.id
+}
+`
@@ -2,13 +2,19 @@ package es
 
 import (
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
 
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	log "github.com/sirupsen/logrus"
 )
 
+// redactedValue is the value returned by Elasticsearch in place of secrets
+const redactedValue = "::es_redacted::"
+
 // diffSuppressIndexTemplate permit to compare template in current state vs from API
 func diffSuppressIndexTemplate(k, old, new string, d *schema.ResourceData) bool {
 	var oo, no map[string]interface{}
@@ -19,25 +25,95 @@ func diffSuppressIndexTemplate(k, old, new string, d *schema.ResourceData) bool
 		return false
 	}
 
-	// Add default parameters on new index template if needed
-	if _, ok := no["order"]; !ok {
-		no["order"] = 0
+	// Template from API is wrapped by its name
+	ot, ok := oo[d.Id()].(map[string]interface{})
+	if !ok {
+		return false
 	}
-	if _, ok := no["settings"]; !ok {
-		no["settings"] = make(map[string]interface{})
+
+	return reflect.DeepEqual(canonical.Normalize(normalizeIndexTemplate(ot), indexTemplateJSONRules), canonical.Normalize(normalizeIndexTemplate(no), indexTemplateJSONRules))
+}
+
+// normalizeIndexTemplate normalize the settings and index patterns of legacy index template
+// so that template from API and from config are comparable
+func normalizeIndexTemplate(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range data {
+		switch k {
+		case "settings":
+			if settings, ok := v.(map[string]interface{}); ok {
+				v = normalizeIndexSettings(settings)
+			}
+		case "index_patterns":
+			if pattern, ok := v.(string); ok {
+				v = []interface{}{pattern}
+			}
+		}
+		result[k] = v
 	}
-	if _, ok := no["mappings"]; !ok {
-		no["mappings"] = make(map[string]interface{})
+
+	return result
+}
+
+// diffSuppressDataStreamTemplate permit to compare composable template in current state vs from API
+func diffSuppressDataStreamTemplate(k, old, new string, d *schema.ResourceData) bool {
+	var oo, no map[string]interface{}
+	if err := json.Unmarshal([]byte(old), &oo); err != nil {
+		return false
 	}
-	if _, ok := no["aliases"]; !ok {
-		no["aliases"] = make(map[string]interface{})
+	if err := json.Unmarshal([]byte(new), &no); err != nil {
+		return false
 	}
 
-	return reflect.DeepEqual(oo[d.Id()], parseAllDotProperties(no))
+	return reflect.DeepEqual(normalizeDataStreamTemplate(oo), normalizeDataStreamTemplate(no))
 }
 
-// diffSuppressDataStreamTemplate permit to compare template in current state vs from API
-func diffSuppressDataStreamTemplate(k, old, new string, d *schema.ResourceData) bool {
+// normalizeDataStreamTemplate remove the default values of composable template
+// and normalize its settings so that template from API and from config are comparable
+func normalizeDataStreamTemplate(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for k, v := range data {
+		result[k] = v
+	}
+
+	if template, ok := result["template"].(map[string]interface{}); ok {
+		normalizedTemplate := make(map[string]interface{})
+		for k, v := range template {
+			if k == "settings" {
+				if settings, ok := v.(map[string]interface{}); ok {
+					v = normalizeIndexSettings(settings)
+				}
+			}
+			if !isEmptyValue(v) {
+				normalizedTemplate[k] = v
+			}
+		}
+		result["template"] = normalizedTemplate
+	}
+
+	// Elasticsearch add data_stream options with false as default value
+	if dataStream, ok := result["data_stream"].(map[string]interface{}); ok {
+		normalizedDataStream := make(map[string]interface{})
+		for k, v := range dataStream {
+			if v != false {
+				normalizedDataStream[k] = v
+			}
+		}
+		result["data_stream"] = normalizedDataStream
+	}
+
+	for _, key := range []string{"template", "composed_of", "_meta"} {
+		if isEmptyValue(result[key]) {
+			delete(result, key)
+		}
+	}
+
+	return result
+}
+
+// suppressEquivalentIndexSettings permit to compare index settings from API, nested with string values,
+// with settings from config
+func suppressEquivalentIndexSettings(k, old, new string, d *schema.ResourceData) bool {
 	var oo, no map[string]interface{}
 	if err := json.Unmarshal([]byte(old), &oo); err != nil {
 		return false
@@ -46,25 +122,85 @@ func diffSuppressDataStreamTemplate(k, old, new string, d *schema.ResourceData)
 		return false
 	}
 
-	// Add default parameters on new index template if needed
-	if _, ok := no["order"]; !ok {
-		no["order"] = 0
+	return reflect.DeepEqual(normalizeIndexSettings(oo), normalizeIndexSettings(no))
+}
+
+// normalizeIndexSettings flatten settings with dot keys, add the index prefix and convert values as string
+// like Elasticsearch return them
+func normalizeIndexSettings(settings map[string]interface{}) map[string]interface{} {
+	flattenSettings := make(map[string]interface{})
+	flattenIndexSettings("", settings, flattenSettings)
+
+	result := make(map[string]interface{})
+	for k, v := range flattenSettings {
+		if !strings.HasPrefix(k, "index.") {
+			k = "index." + k
+		}
+		result[k] = v
 	}
-	if _, ok := no["settings"]; !ok {
-		no["settings"] = make(map[string]interface{})
+
+	return result
+}
+
+// flattenIndexSettings handle the recursivity to flatten settings
+func flattenIndexSettings(prefix string, value interface{}, result map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if prefix != "" {
+				k = prefix + "." + k
+			}
+			flattenIndexSettings(k, child, result)
+		}
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			values = append(values, convertSettingValueToString(item))
+		}
+		result[prefix] = values
+	default:
+		result[prefix] = convertSettingValueToString(v)
 	}
-	if _, ok := no["mappings"]; !ok {
-		no["mappings"] = make(map[string]interface{})
+}
+
+// convertSettingValueToString convert scalar setting value as string
+func convertSettingValueToString(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return nil
+	default:
+		return fmt.Sprintf("%v", v)
 	}
-	if _, ok := no["aliases"]; !ok {
-		no["aliases"] = make(map[string]interface{})
+}
+
+// isEmptyValue check if value is nil, empty map or empty list
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
 	}
 
-	return reflect.DeepEqual(oo[d.Id()], parseAllDotProperties(no))
+	return false
 }
 
 // suppressEquivalentJSON permit to compare state store as JSON string
 func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
+	return canonical.Equal(old, new, nil)
+}
+
+// suppressEquivalentRedactedJSON permit to compare JSON string where Elasticsearch return secrets as redacted
+// A redacted value is equal to any value, or to no value when the secret is provided in another attribute
+func suppressEquivalentRedactedJSON(k, old, new string, d *schema.ResourceData) bool {
 	var oldObj, newObj interface{}
 	if err := json.Unmarshal([]byte(old), &oldObj); err != nil {
 		return false
@@ -72,7 +208,59 @@ func suppressEquivalentJSON(k, old, new string, d *schema.ResourceData) bool {
 	if err := json.Unmarshal([]byte(new), &newObj); err != nil {
 		return false
 	}
-	return reflect.DeepEqual(oldObj, newObj)
+	return reflect.DeepEqual(canonical.Normalize(replaceRedactedValues(oldObj, newObj), nil), canonical.Normalize(newObj, nil))
+}
+
+// replaceRedactedValues replace redacted values from old object by the values of new object
+func replaceRedactedValues(oldObj, newObj interface{}) interface{} {
+	switch oldValue := oldObj.(type) {
+	case map[string]interface{}:
+		newMap, _ := newObj.(map[string]interface{})
+
+		result := make(map[string]interface{})
+		for key, value := range oldValue {
+			newValue, hasNewValue := newMap[key]
+			if value == redactedValue {
+				if hasNewValue {
+					result[key] = newValue
+				}
+				continue
+			}
+			result[key] = replaceRedactedValues(value, newValue)
+		}
+		return result
+	case []interface{}:
+		newList, _ := newObj.([]interface{})
+
+		result := make([]interface{}, 0, len(oldValue))
+		for i, value := range oldValue {
+			var newValue interface{}
+			if i < len(newList) {
+				newValue = newList[i]
+			}
+			if value == redactedValue && newValue != nil {
+				result = append(result, newValue)
+				continue
+			}
+			result = append(result, replaceRedactedValues(value, newValue))
+		}
+		return result
+	default:
+		return oldObj
+	}
+}
+
+// suppressEquivalentDuration permit to compare Elasticsearch durations like 5m and 300000ms
+func suppressEquivalentDuration(k, old, new string, d *schema.ResourceData) bool {
+	oldDuration, err := parseElasticsearchDuration(old)
+	if err != nil {
+		return false
+	}
+	newDuration, err := parseElasticsearchDuration(new)
+	if err != nil {
+		return false
+	}
+	return oldDuration == newDuration
 }
 
 // suppressLicense permit to compare license in current state VS API
@@ -96,43 +284,7 @@ func suppressLicense(k, old, new string, d *schema.ResourceData) bool {
 	return reflect.DeepEqual(oldObj, newObj)
 }
 
-// parseAllDotProperties permit to convert elasticsearch attributes with dot in sub structure
-func parseAllDotProperties(data map[string]interface{}) map[string]interface{} {
-
-	result := make(map[string]interface{})
-	for k, v := range data {
-		rv := reflect.ValueOf(v)
-		if rv.Kind() == reflect.Map {
-			parseDotPropertie(k, parseAllDotProperties(v.(map[string]interface{})), result)
-		} else {
-			parseDotPropertie(k, v, result)
-		}
-	}
-
-	return result
-}
-
-// parseDotPropertie handle the recursivity to transform attribute that contain dot in sub structure
-func parseDotPropertie(key string, value interface{}, result map[string]interface{}) {
-	if strings.Contains(key, ".") {
-		listKey := strings.Split(key, ".")
-		if _, ok := result[listKey[0]]; !ok {
-			result[listKey[0]] = make(map[string]interface{})
-		}
-		parseDotPropertie(strings.Join(listKey[1:], "."), value, result[listKey[0]].(map[string]interface{}))
-	} else {
-		result[key] = value
-	}
-}
-
+// diffSuppressIngestPipeline permit to compare pipeline in current state vs from API
 func diffSuppressIngestPipeline(k, old, new string, d *schema.ResourceData) bool {
-	var oo, no interface{}
-	if err := json.Unmarshal([]byte(old), &oo); err != nil {
-		return false
-	}
-	if err := json.Unmarshal([]byte(new), &no); err != nil {
-		return false
-	}
-
-	return reflect.DeepEqual(oo, no)
-}
\ No newline at end of file
+	return canonical.Equal(old, new, ingestPipelineJSONRules)
+}
@@ -0,0 +1,192 @@
+package es
+
+import (
+	"testing"
+)
+
+func TestSuppressEquivalentRedactedJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		suppress bool
+	}{
+		{
+			name:     "same value",
+			old:      `{"a": {"password": "secret"}}`,
+			new:      `{"a": {"password": "secret"}}`,
+			suppress: true,
+		},
+		{
+			name:     "redacted value",
+			old:      `{"a": {"webhook": {"auth": {"basic": {"username": "user", "password": "::es_redacted::"}}}}}`,
+			new:      `{"a": {"webhook": {"auth": {"basic": {"username": "user", "password": "secret"}}}}}`,
+			suppress: true,
+		},
+		{
+			name:     "redacted value provided by secrets",
+			old:      `{"a": {"webhook": {"auth": {"basic": {"username": "user", "password": "::es_redacted::"}}}}}`,
+			new:      `{"a": {"webhook": {"auth": {"basic": {"username": "user"}}}}}`,
+			suppress: true,
+		},
+		{
+			name:     "other value changed",
+			old:      `{"a": {"webhook": {"auth": {"basic": {"username": "user", "password": "::es_redacted::"}}}}}`,
+			new:      `{"a": {"webhook": {"auth": {"basic": {"username": "other", "password": "secret"}}}}}`,
+			suppress: false,
+		},
+		{
+			name:     "redacted value in list",
+			old:      `{"a": {"webhook": {"headers": [{"name": "token", "value": "::es_redacted::"}, "::es_redacted::"]}}}`,
+			new:      `{"a": {"webhook": {"headers": [{"name": "token", "value": "secret"}, "secret"]}}}`,
+			suppress: true,
+		},
+		{
+			name:     "other value changed in list",
+			old:      `{"a": {"webhook": {"headers": [{"name": "token", "value": "::es_redacted::"}]}}}`,
+			new:      `{"a": {"webhook": {"headers": [{"name": "other", "value": "secret"}]}}}`,
+			suppress: false,
+		},
+		{
+			name:     "not redacted value removed",
+			old:      `{"a": {"password": "secret"}}`,
+			new:      `{"a": {}}`,
+			suppress: false,
+		},
+	}
+
+	for _, c := range cases {
+		if suppress := suppressEquivalentRedactedJSON("actions", c.old, c.new, nil); suppress != c.suppress {
+			t.Errorf("%s: expected suppress to be %t", c.name, c.suppress)
+		}
+	}
+}
+
+func TestDiffSuppressDataStreamTemplate(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		suppress bool
+	}{
+		{
+			name:     "defaults from API",
+			old:      `{"index_patterns": ["test*"], "composed_of": [], "data_stream": {"hidden": false}, "priority": 20}`,
+			new:      `{"index_patterns": ["test*"], "data_stream": {}, "priority": 20}`,
+			suppress: true,
+		},
+		{
+			name:     "nested and string settings from API",
+			old:      `{"index_patterns": ["test*"], "template": {"settings": {"index": {"lifecycle": {"name": "policy"}, "number_of_shards": "1"}}}}`,
+			new:      `{"index_patterns": ["test*"], "template": {"settings": {"index.lifecycle.name": "policy", "number_of_shards": 1}}}`,
+			suppress: true,
+		},
+		{
+			name:     "empty template",
+			old:      `{"index_patterns": ["test*"]}`,
+			new:      `{"index_patterns": ["test*"], "template": {"settings": {}}}`,
+			suppress: true,
+		},
+		{
+			name:     "setting changed",
+			old:      `{"index_patterns": ["test*"], "template": {"settings": {"index": {"number_of_shards": "1"}}}}`,
+			new:      `{"index_patterns": ["test*"], "template": {"settings": {"number_of_shards": 2}}}`,
+			suppress: false,
+		},
+		{
+			name:     "priority changed",
+			old:      `{"index_patterns": ["test*"], "priority": 20}`,
+			new:      `{"index_patterns": ["test*"], "priority": 23}`,
+			suppress: false,
+		},
+	}
+
+	for _, c := range cases {
+		if suppress := diffSuppressDataStreamTemplate("template", c.old, c.new, nil); suppress != c.suppress {
+			t.Errorf("%s: expected suppress to be %t", c.name, c.suppress)
+		}
+	}
+}
+
+func TestSuppressEquivalentIndexSettings(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		suppress bool
+	}{
+		{
+			name:     "nested and string settings from API",
+			old:      `{"index": {"lifecycle": {"name": "policy"}, "number_of_shards": "1"}}`,
+			new:      `{"index.lifecycle.name": "policy", "number_of_shards": 1}`,
+			suppress: true,
+		},
+		{
+			name:     "boolean and list settings",
+			old:      `{"index": {"hidden": "true", "sort": {"field": ["date"]}}}`,
+			new:      `{"index.hidden": true, "index.sort.field": ["date"]}`,
+			suppress: true,
+		},
+		{
+			name:     "setting changed",
+			old:      `{"index": {"number_of_shards": "1"}}`,
+			new:      `{"number_of_shards": 2}`,
+			suppress: false,
+		},
+		{
+			name:     "setting added",
+			old:      `{"index": {"number_of_shards": "1"}}`,
+			new:      `{"number_of_shards": 1, "number_of_replicas": 0}`,
+			suppress: false,
+		},
+	}
+
+	for _, c := range cases {
+		if suppress := suppressEquivalentIndexSettings("settings", c.old, c.new, nil); suppress != c.suppress {
+			t.Errorf("%s: expected suppress to be %t", c.name, c.suppress)
+		}
+	}
+}
+
+func TestDiffSuppressIndexTemplate(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		suppress bool
+	}{
+		{
+			name:     "dotted and number settings",
+			old:      `{"terraform-test": {"order": 2, "index_patterns": ["test*"], "settings": {"index": {"number_of_shards": "1", "refresh_interval": "5s"}}, "mappings": {}, "aliases": {}}}`,
+			new:      `{"order": 2, "index_patterns": ["test*"], "settings": {"index.number_of_shards": 1, "refresh_interval": "5s"}}`,
+			suppress: true,
+		},
+		{
+			name:     "default order and single pattern",
+			old:      `{"terraform-test": {"order": 0, "index_patterns": ["test*"], "settings": {}, "mappings": {}, "aliases": {}}}`,
+			new:      `{"index_patterns": "test*"}`,
+			suppress: true,
+		},
+		{
+			name:     "ownership marker",
+			old:      `{"terraform-test": {"order": 0, "index_patterns": ["test*"], "settings": {}, "mappings": {"_meta": {"managed_by": {"workspace": "prod"}}}, "aliases": {}}}`,
+			new:      `{"index_patterns": ["test*"]}`,
+			suppress: true,
+		},
+		{
+			name:     "setting changed",
+			old:      `{"terraform-test": {"order": 2, "index_patterns": ["test*"], "settings": {"index": {"refresh_interval": "5s"}}}}`,
+			new:      `{"order": 2, "index_patterns": ["test*"], "settings": {"index.refresh_interval": "3s"}}`,
+			suppress: false,
+		},
+	}
+
+	d := resourceElasticsearchIndexTemplate().TestResourceData()
+	d.SetId("terraform-test")
+
+	for _, c := range cases {
+		if suppress := diffSuppressIndexTemplate("template", c.old, c.new, d); suppress != c.suppress {
+			t.Errorf("%s: expected suppress to be %t", c.name, c.suppress)
+		}
+	}
+}
@@ -19,6 +19,12 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// providerConf is the provider configuration shared with resources
+type providerConf struct {
+	client     *elastic.Client
+	createOnly bool
+}
+
 // Provider permiit to init the terraform provider
 func Provider() terraform.ResourceProvider {
 	return &schema.Provider{
@@ -65,10 +71,17 @@ func Provider() terraform.ResourceProvider {
 				Default:     10,
 				Description: "Wait time in second before retry connexion",
 			},
+			"create_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_CREATE_ONLY", false),
+				Description: "Fail to create object that already exist instead of overwrite it. Existing objects must be imported before being managed, or be overwritten with takeover on resources that support it",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
 			"elasticsearch_index_lifecycle_policy":     resourceElasticsearchIndexLifecyclePolicy(),
+			"elasticsearch_index_lifecycle_step":       resourceElasticsearchIndexLifecycleStep(),
 			"elasticsearch_index_template":             resourceElasticsearchIndexTemplate(),
 			"elasticsearch_role":                       resourceElasticsearchSecurityRole(),
 			"elasticsearch_role_mapping":               resourceElasticsearchSecurityRoleMapping(),
@@ -77,10 +90,19 @@ func Provider() terraform.ResourceProvider {
 			"elasticsearch_snapshot_repository":        resourceElasticsearchSnapshotRepository(),
 			"elasticsearch_snapshot_lifecycle_policy":  resourceElasticsearchSnapshotLifecyclePolicy(),
 			"elasticsearch_watcher":                    resourceElasticsearchWatcher(),
+			"elasticsearch_watch_ack":                  resourceElasticsearchWatchAck(),
 			"elasticsearch_xpack_data_stream_template": resourceElasticsearchDataStreamTemplate(),
+			"elasticsearch_composable_index_template":  resourceElasticsearchComposableIndexTemplate(),
 			"elasticsearch_ingest_pipeline":            resourceElasticsearchIngestPipeline(),
 		},
 
+		DataSourcesMap: map[string]*schema.Resource{
+			"elasticsearch_index_lifecycle_explain": dataSourceElasticsearchIndexLifecycleExplain(),
+			"elasticsearch_watch_status":            dataSourceElasticsearchWatchStatus(),
+			"elasticsearch_ingest_pipeline":         dataSourceElasticsearchIngestPipeline(),
+			"elasticsearch_index_template":          dataSourceElasticsearchIndexTemplate(),
+		},
+
 		ConfigureFunc: providerConfigure,
 	}
 }
@@ -168,5 +190,8 @@ func providerConfigure(d *schema.ResourceData) (interface{}, error) {
 		return nil, errors.Errorf("ElasticSearch version is not 7.x (%s), you need to use the right version of elasticsearch provider", version)
 	}
 
-	return client, nil
+	return &providerConf{
+		client:     client,
+		createOnly: d.Get("create_only").(bool),
+	}, nil
 }
@@ -0,0 +1,933 @@
+// Manage composable index template in Elasticsearch
+// API documentation:
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/index-templates.html
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-simulate-template.html
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/migrate-index-template.html
+// Supported version:
+//  - v7
+
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// ComposableIndexTemplates is the get index template API response
+type ComposableIndexTemplates struct {
+	IndexTemplates []*ComposableIndexTemplate `json:"index_templates"`
+}
+
+// ComposableIndexTemplate is one composable template returned by get index template API
+type ComposableIndexTemplate struct {
+	Name          string                       `json:"name"`
+	IndexTemplate *ComposableIndexTemplateSpec `json:"index_template"`
+}
+
+// ComposableIndexTemplateSpec is the composable template object
+type ComposableIndexTemplateSpec struct {
+	IndexPatterns []string                           `json:"index_patterns"`
+	ComposedOf    []string                           `json:"composed_of,omitempty"`
+	Priority      int                                `json:"priority,omitempty"`
+	Version       int                                `json:"version,omitempty"`
+	DataStream    *ComposableIndexTemplateDataStream `json:"data_stream,omitempty"`
+	Meta          interface{}                        `json:"_meta,omitempty"`
+	Template      *ComposableIndexTemplateTemplate   `json:"template,omitempty"`
+}
+
+// ComposableIndexTemplateDataStream is the data stream options of composable template
+type ComposableIndexTemplateDataStream struct {
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// ComposableIndexTemplateTemplate is the index configuration applied by composable template
+type ComposableIndexTemplateTemplate struct {
+	Settings interface{} `json:"settings,omitempty"`
+	Mappings interface{} `json:"mappings,omitempty"`
+	Aliases  interface{} `json:"aliases,omitempty"`
+}
+
+// ComposableIndexTemplateSimulation is the simulate index API response
+type ComposableIndexTemplateSimulation struct {
+	Template    *ComposableIndexTemplateTemplate      `json:"template"`
+	Overlapping []*ComposableIndexTemplateOverlapping `json:"overlapping,omitempty"`
+}
+
+// ComposableIndexTemplateOverlapping is a template that match the index with a lower priority
+type ComposableIndexTemplateOverlapping struct {
+	Name          string   `json:"name"`
+	IndexPatterns []string `json:"index_patterns"`
+}
+
+// resourceElasticsearchComposableIndexTemplate handle the composable index template API call
+func resourceElasticsearchComposableIndexTemplate() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceElasticsearchComposableIndexTemplateCreate,
+		Update: resourceElasticsearchComposableIndexTemplateUpdate,
+		Read:   resourceElasticsearchComposableIndexTemplateRead,
+		Delete: resourceElasticsearchComposableIndexTemplateDelete,
+
+		CustomizeDiff: resourceElasticsearchComposableIndexTemplateCustomizeDiff,
+
+		Importer: &schema.ResourceImporter{
+			State: importStateManagedBy("Composable index template", getComposableIndexTemplateManagedBy),
+		},
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceElasticsearchComposableIndexTemplateV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceElasticsearchComposableIndexTemplateStateUpgradeV0,
+			},
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				ForceNew: true,
+				Required: true,
+			},
+			"index_patterns": {
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"composed_of": {
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"priority": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"version": {
+				Type:     schema.TypeInt,
+				Optional: true,
+			},
+			"data_stream": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"hidden": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"meta": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Description:      "The _meta JSON object",
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJSON,
+				StateFunc:        canonicalJSONStateFunc(nil),
+			},
+			"template": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"settings": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentIndexSettings,
+						},
+						"mappings": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+						},
+						"aliases": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+						},
+					},
+				},
+			},
+			"simulate_index": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Index name used to simulate the template, default to the first index pattern with wildcards replaced by simulate",
+			},
+			"resolved_template": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the template that win for simulate_index, computed at plan time",
+			},
+			"resolved_settings": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Settings of the template merged with its component templates, computed at plan time",
+			},
+			"resolved_mappings": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Mappings of the template merged with its component templates, computed at plan time",
+			},
+			"verify_legacy_migration": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Name of the legacy template replaced by this template. On create, the plan fails if the template differ from the legacy template converted to composable template (order become priority, settings, mappings and aliases move in template), the error show the converted template. It's only a verification: the legacy template is neither converted nor deleted",
+			},
+			"overlapping_legacy_templates": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Legacy templates with overlapping index patterns, they are ignored for indices matching this template",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"managed_by": managedBySchema(),
+			"takeover":   takeoverSchema(),
+		},
+	}
+}
+
+// resourceElasticsearchDataStreamTemplate is the deprecated alias of elasticsearch_composable_index_template
+// It keep the template as JSON string, so that existing configurations still apply without diff
+func resourceElasticsearchDataStreamTemplate() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceElasticsearchDataStreamTemplateCreate,
+		Update: resourceElasticsearchDataStreamTemplateUpdate,
+		Read:   resourceElasticsearchDataStreamTemplateRead,
+		Delete: resourceElasticsearchComposableIndexTemplateDelete,
+
+		DeprecationMessage: "elasticsearch_xpack_data_stream_template is deprecated, use elasticsearch_composable_index_template instead",
+
+		Importer: &schema.ResourceImporter{
+			State: importStateManagedBy("Composable index template", getComposableIndexTemplateManagedBy),
+		},
+
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceElasticsearchComposableIndexTemplateV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceElasticsearchDataStreamTemplateStateUpgradeV0,
+			},
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				ForceNew: true,
+				Required: true,
+			},
+			"template": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: diffSuppressDataStreamTemplate,
+			},
+			"managed_by": managedBySchema(),
+			"takeover":   takeoverSchema(),
+		},
+	}
+}
+
+// resourceElasticsearchComposableIndexTemplateV0 is the schema when the template was a JSON string
+func resourceElasticsearchComposableIndexTemplateV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				ForceNew: true,
+				Required: true,
+			},
+			"template": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+		},
+	}
+}
+
+// resourceElasticsearchComposableIndexTemplateStateUpgradeV0 convert template JSON string to attributes
+func resourceElasticsearchComposableIndexTemplateStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	template, err := decodeComposableIndexTemplateStateV0(rawState)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns := make([]interface{}, 0, len(template.IndexPatterns))
+	for _, pattern := range template.IndexPatterns {
+		patterns = append(patterns, pattern)
+	}
+	composedOf := make([]interface{}, 0, len(template.ComposedOf))
+	for _, component := range template.ComposedOf {
+		composedOf = append(composedOf, component)
+	}
+
+	rawState["index_patterns"] = patterns
+	rawState["composed_of"] = composedOf
+	rawState["priority"] = template.Priority
+	rawState["version"] = template.Version
+	rawState["data_stream"] = flattenComposableIndexTemplateDataStream(template.DataStream)
+	rawState["meta"] = convertInterfaceToJSONString(template.Meta)
+	rawState["template"] = flattenComposableIndexTemplateTemplate(template.Template)
+
+	return rawState, nil
+}
+
+// resourceElasticsearchDataStreamTemplateStateUpgradeV0 replace the get index template API response stored in template
+// by the template itself, as returned on read
+func resourceElasticsearchDataStreamTemplateStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	template, err := decodeComposableIndexTemplateStateV0(rawState)
+	if err != nil {
+		return nil, err
+	}
+
+	rawState["template"] = convertInterfaceToJSONString(template)
+
+	return rawState, nil
+}
+
+// decodeComposableIndexTemplateStateV0 decode the template JSON string of state V0
+// The template can be wrapped in index_templates, as stored by read before the template was unwrapped
+func decodeComposableIndexTemplateStateV0(rawState map[string]interface{}) (*ComposableIndexTemplateSpec, error) {
+	template := &ComposableIndexTemplateSpec{}
+	body, ok := rawState["template"].(string)
+	if !ok || body == "" {
+		return template, nil
+	}
+
+	wrapper := &ComposableIndexTemplates{}
+	if err := json.Unmarshal([]byte(body), wrapper); err != nil {
+		return nil, errors.Wrap(err, "Error when upgrade composable index template")
+	}
+	if wrapper.IndexTemplates == nil {
+		if err := json.Unmarshal([]byte(body), template); err != nil {
+			return nil, errors.Wrap(err, "Error when upgrade composable index template")
+		}
+		return template, nil
+	}
+
+	id, _ := rawState["id"].(string)
+	for _, t := range wrapper.IndexTemplates {
+		if t.Name == id && t.IndexTemplate != nil {
+			return t.IndexTemplate, nil
+		}
+	}
+
+	return template, nil
+}
+
+// resourceElasticsearchComposableIndexTemplateCreate create composable index template
+func resourceElasticsearchComposableIndexTemplateCreate(d *schema.ResourceData, meta interface{}) error {
+
+	err := createComposableIndexTemplate(d, meta)
+	if err != nil {
+		return err
+	}
+	d.SetId(d.Get("name").(string))
+	return resourceElasticsearchComposableIndexTemplateRead(d, meta)
+}
+
+// resourceElasticsearchComposableIndexTemplateUpdate update composable index template
+func resourceElasticsearchComposableIndexTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := createComposableIndexTemplate(d, meta)
+	if err != nil {
+		return err
+	}
+	return resourceElasticsearchComposableIndexTemplateRead(d, meta)
+}
+
+// resourceElasticsearchComposableIndexTemplateRead read composable index template
+func resourceElasticsearchComposableIndexTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+	client := meta.(*providerConf).client
+
+	template, err := getComposableIndexTemplate(id, client)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		fmt.Printf("[WARN] Composable index template %s not found - removing from state", id)
+		log.Warnf("Composable index template %s not found - removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	log.Debugf("Get composable index template %s successfully:\n%+v", id, template)
+
+	d.Set("name", d.Id())
+	d.Set("index_patterns", template.IndexPatterns)
+	d.Set("composed_of", template.ComposedOf)
+	d.Set("priority", template.Priority)
+	d.Set("version", template.Version)
+	d.Set("data_stream", flattenComposableIndexTemplateDataStream(template.DataStream))
+	d.Set("meta", convertInterfaceToJSONString(removeManagedBy(template.Meta)))
+	d.Set("managed_by", getManagedBy(template.Meta, nil))
+	d.Set("template", flattenComposableIndexTemplateTemplate(template.Template))
+
+	return nil
+}
+
+// resourceElasticsearchDataStreamTemplateCreate create composable index template from JSON string
+func resourceElasticsearchDataStreamTemplateCreate(d *schema.ResourceData, meta interface{}) error {
+
+	err := createDataStreamTemplate(d, meta)
+	if err != nil {
+		return err
+	}
+	d.SetId(d.Get("name").(string))
+	return resourceElasticsearchDataStreamTemplateRead(d, meta)
+}
+
+// resourceElasticsearchDataStreamTemplateUpdate update composable index template from JSON string
+func resourceElasticsearchDataStreamTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := createDataStreamTemplate(d, meta)
+	if err != nil {
+		return err
+	}
+	return resourceElasticsearchDataStreamTemplateRead(d, meta)
+}
+
+// resourceElasticsearchDataStreamTemplateRead read composable index template as JSON string
+func resourceElasticsearchDataStreamTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+	client := meta.(*providerConf).client
+
+	template, err := getComposableIndexTemplate(id, client)
+	if err != nil {
+		return err
+	}
+	if template == nil {
+		fmt.Printf("[WARN] Composable index template %s not found - removing from state", id)
+		log.Warnf("Composable index template %s not found - removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	log.Debugf("Get composable index template %s successfully:\n%+v", id, template)
+
+	managedBy := getManagedBy(template.Meta, nil)
+	template.Meta = removeManagedBy(template.Meta)
+
+	d.Set("name", d.Id())
+	d.Set("template", convertInterfaceToJSONString(template))
+	d.Set("managed_by", managedBy)
+
+	return nil
+}
+
+// resourceElasticsearchComposableIndexTemplateCustomizeDiff check priority collision with other templates
+// and simulate the template at plan time
+func resourceElasticsearchComposableIndexTemplateCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	attributes := []string{"name", "index_patterns", "composed_of", "priority", "version", "data_stream", "meta", "template", "simulate_index", "verify_legacy_migration"}
+
+	hasChange := d.Id() == ""
+	known := true
+	for _, attribute := range attributes {
+		if d.HasChange(attribute) {
+			hasChange = true
+		}
+		if !d.NewValueKnown(attribute) {
+			known = false
+		}
+	}
+	if !hasChange {
+		return nil
+	}
+
+	if !known {
+		for _, attribute := range []string{"resolved_template", "resolved_settings", "resolved_mappings", "overlapping_legacy_templates"} {
+			if err := d.SetNewComputed(attribute); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	name := d.Get("name").(string)
+	template := buildComposableIndexTemplate(d.Get)
+
+	client := meta.(*providerConf).client
+	templates, err := getComposableIndexTemplates("", client)
+	if err != nil {
+		return err
+	}
+	if err := checkComposableIndexTemplatePriority(name, template, templates); err != nil {
+		return err
+	}
+
+	if d.Id() == "" && d.Get("verify_legacy_migration").(string) != "" {
+		if err := checkComposableIndexTemplateMigration(d.Get("verify_legacy_migration").(string), template, client); err != nil {
+			return err
+		}
+	}
+
+	legacyTemplates, err := getIndexTemplatesLegacy("", client)
+	if err != nil {
+		return err
+	}
+	legacyPatterns := make(map[string][]string, len(legacyTemplates))
+	for legacyName, legacyTemplate := range legacyTemplates {
+		legacyPatterns[legacyName] = []string(legacyTemplate.IndexPatterns)
+	}
+	if err := d.SetNew("overlapping_legacy_templates", overlappingIndexTemplates(template.IndexPatterns, legacyPatterns)); err != nil {
+		return err
+	}
+
+	// The planned template replace the stored one with the same name, so that they are not seen as overlapping
+	simulation, err := simulateComposableIndexTemplate(name, template, client)
+	if err != nil {
+		return err
+	}
+
+	index := composableIndexTemplateSimulateIndex(d.Get("simulate_index").(string), template)
+
+	// The simulated template replace the current one with the same name
+	resolvedTemplates := []*ComposableIndexTemplate{
+		{
+			Name:          name,
+			IndexTemplate: template,
+		},
+	}
+	for _, t := range templates {
+		if t.Name != name {
+			resolvedTemplates = append(resolvedTemplates, t)
+		}
+	}
+
+	if err := d.SetNew("resolved_template", resolveComposableIndexTemplate(index, resolvedTemplates)); err != nil {
+		return err
+	}
+	if err := d.SetNew("resolved_settings", convertInterfaceToJSONString(simulation.Template.Settings)); err != nil {
+		return err
+	}
+	return d.SetNew("resolved_mappings", convertInterfaceToJSONString(simulation.Template.Mappings))
+}
+
+// resourceElasticsearchComposableIndexTemplateDelete delete composable index template
+func resourceElasticsearchComposableIndexTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+
+	id := d.Id()
+
+	client := meta.(*providerConf).client
+	res, err := client.API.Indices.DeleteIndexTemplate(
+		id,
+		client.API.Indices.DeleteIndexTemplate.WithContext(context.Background()),
+		client.API.Indices.DeleteIndexTemplate.WithPretty(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			fmt.Printf("[WARN] Composable index template %s not found - removing from state", id)
+			log.Warnf("Composable index template %s not found - removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return errors.Errorf("Error when delete composable index template %s: %s", id, res.String())
+
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// createComposableIndexTemplate create or update composable index template
+func createComposableIndexTemplate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	client := meta.(*providerConf).client
+
+	current, err := getComposableIndexTemplate(name, client)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		if err := checkManagedBy("Composable index template", name, getManagedBy(current.Meta, nil), d); err != nil {
+			return err
+		}
+	}
+
+	template := buildComposableIndexTemplate(d.Get)
+	if d.Id() == "" && d.Get("verify_legacy_migration").(string) != "" {
+		if err := checkComposableIndexTemplateMigration(d.Get("verify_legacy_migration").(string), template, client); err != nil {
+			return err
+		}
+	}
+
+	templateMeta, err := stampManagedBy(convertInterfaceToJSONString(template.Meta), nil, d.Get("managed_by").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+	template.Meta = optionalInterfaceJSON(templateMeta)
+
+	return putComposableIndexTemplate(name, template, isCreateOnly(d, meta), client)
+}
+
+// createDataStreamTemplate create or update composable index template from JSON string
+func createDataStreamTemplate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	client := meta.(*providerConf).client
+
+	current, err := getComposableIndexTemplate(name, client)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		if err := checkManagedBy("Composable index template", name, getManagedBy(current.Meta, nil), d); err != nil {
+			return err
+		}
+	}
+
+	template, err := stampManagedBy(d.Get("template").(string), []string{"_meta"}, d.Get("managed_by").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+
+	return putComposableIndexTemplate(name, json.RawMessage(template), isCreateOnly(d, meta), client)
+}
+
+// putComposableIndexTemplate create or update composable index template object
+// The template is the composable template object or its JSON
+// If create is true, it failed if the template already exist
+func putComposableIndexTemplate(name string, template interface{}, create bool, client *elastic.Client) error {
+	b, err := json.Marshal(template)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Put composable index template %s: %s", name, string(b))
+
+	res, err := client.API.Indices.PutIndexTemplate(
+		name,
+		bytes.NewReader(b),
+		client.API.Indices.PutIndexTemplate.WithCreate(create),
+		client.API.Indices.PutIndexTemplate.WithContext(context.Background()),
+		client.API.Indices.PutIndexTemplate.WithPretty(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		if create && isAlreadyExistResponse(res) {
+			return alreadyExistError("Composable index template", name)
+		}
+		return errors.Errorf("Error when add composable index template %s: %s", name, res.String())
+	}
+
+	return nil
+}
+
+// getComposableIndexTemplate return the composable template without the index_templates wrapper returned by the API
+// It return nil if template not exist
+func getComposableIndexTemplate(id string, client *elastic.Client) (*ComposableIndexTemplateSpec, error) {
+	templates, err := getComposableIndexTemplates(id, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, template := range templates {
+		if template.Name == id {
+			return template.IndexTemplate, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getComposableIndexTemplateManagedBy return the ownership marker of composable template
+func getComposableIndexTemplateManagedBy(id string, meta interface{}) (map[string]interface{}, error) {
+	template, err := getComposableIndexTemplate(id, meta.(*providerConf).client)
+	if err != nil || template == nil {
+		return nil, err
+	}
+
+	return getManagedBy(template.Meta, nil), nil
+}
+
+// getComposableIndexTemplates return the composable templates matching name, or all templates if name is empty
+func getComposableIndexTemplates(name string, client *elastic.Client) ([]*ComposableIndexTemplate, error) {
+	options := []func(*esapi.IndicesGetIndexTemplateRequest){
+		client.API.Indices.GetIndexTemplate.WithContext(context.Background()),
+		client.API.Indices.GetIndexTemplate.WithPretty(),
+	}
+	if name != "" {
+		options = append(options, client.API.Indices.GetIndexTemplate.WithName(name))
+	}
+
+	res, err := client.API.Indices.GetIndexTemplate(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return nil, nil
+		}
+		return nil, errors.Errorf("Error when get composable index template %s: %s", name, res.String())
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	templates := &ComposableIndexTemplates{}
+	if err := json.Unmarshal(b, templates); err != nil {
+		return nil, err
+	}
+
+	return templates.IndexTemplates, nil
+}
+
+// simulateComposableIndexTemplate return the configuration applied by template merged with its component templates
+// The template replace the existing template with the same name for the simulation
+func simulateComposableIndexTemplate(name string, template *ComposableIndexTemplateSpec, client *elastic.Client) (*ComposableIndexTemplateSimulation, error) {
+	data, err := json.Marshal(template)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.API.Indices.SimulateTemplate(
+		client.API.Indices.SimulateTemplate.WithName(name),
+		client.API.Indices.SimulateTemplate.WithBody(bytes.NewReader(data)),
+		client.API.Indices.SimulateTemplate.WithContext(context.Background()),
+		client.API.Indices.SimulateTemplate.WithPretty(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, errors.Errorf("Error when simulate composable index template %s: %s", name, res.String())
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	simulation := &ComposableIndexTemplateSimulation{}
+	if err := json.Unmarshal(b, simulation); err != nil {
+		return nil, err
+	}
+	if simulation.Template == nil {
+		simulation.Template = &ComposableIndexTemplateTemplate{}
+	}
+
+	log.Debugf("Simulate composable index template %s: %s", name, string(b))
+
+	return simulation, nil
+}
+
+// checkComposableIndexTemplatePriority check there are no other template with the same priority and overlapping index patterns
+func checkComposableIndexTemplatePriority(name string, template *ComposableIndexTemplateSpec, templates []*ComposableIndexTemplate) error {
+	for _, other := range templates {
+		if other.Name == name || other.IndexTemplate.Priority != template.Priority {
+			continue
+		}
+		for _, pattern := range template.IndexPatterns {
+			for _, otherPattern := range other.IndexTemplate.IndexPatterns {
+				if indexPatternsOverlap(pattern, otherPattern) {
+					return errors.Errorf("Template %s has index pattern %s overlapping with pattern %s of template %s with the same priority %d", name, pattern, otherPattern, other.Name, template.Priority)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// indexTemplateMigrationMappingsJSONRules ignore the ownership marker of the legacy template in mappings
+var indexTemplateMigrationMappingsJSONRules = &canonical.Rules{
+	Defaults: map[string]interface{}{
+		"_meta": map[string]interface{}{},
+	},
+	ServerFields: []string{"_meta." + managedByKey},
+}
+
+// checkComposableIndexTemplateMigration check the template has the same content than the converted legacy template,
+// so that indices are created the same way once the composable template take precedence
+func checkComposableIndexTemplateMigration(legacyName string, template *ComposableIndexTemplateSpec, client *elastic.Client) error {
+	legacyTemplates, err := getIndexTemplatesLegacy(legacyName, client)
+	if err != nil {
+		return err
+	}
+	if legacyTemplates[legacyName] == nil {
+		return errors.Errorf("Legacy index template %s not found", legacyName)
+	}
+
+	migration := convertIndexTemplateLegacyToComposable(legacyTemplates[legacyName])
+	if diffSuppressDataStreamTemplate("template", composableIndexTemplateMigrationJSON(migration), composableIndexTemplateMigrationJSON(template), nil) {
+		return nil
+	}
+
+	return errors.Errorf("Composable index template differ from legacy index template %s on %s, expected: %s", legacyName, strings.Join(diffComposableIndexTemplateMigration(template, migration), ", "), convertInterfaceToJSONString(migration))
+}
+
+// diffComposableIndexTemplateMigration return the attributes that differ between the template and the converted legacy template
+func diffComposableIndexTemplateMigration(template *ComposableIndexTemplateSpec, migration *ComposableIndexTemplateSpec) []string {
+	var current, expected map[string]interface{}
+	if err := json.Unmarshal([]byte(composableIndexTemplateMigrationJSON(template)), &current); err != nil {
+		return []string{"template"}
+	}
+	if err := json.Unmarshal([]byte(composableIndexTemplateMigrationJSON(migration)), &expected); err != nil {
+		return []string{"template"}
+	}
+	current = normalizeDataStreamTemplate(current)
+	expected = normalizeDataStreamTemplate(expected)
+
+	diffs := make([]string, 0)
+	for _, key := range []string{"index_patterns", "composed_of", "priority", "version", "data_stream"} {
+		if !reflect.DeepEqual(current[key], expected[key]) {
+			diffs = append(diffs, key)
+		}
+	}
+	currentTemplate, _ := current["template"].(map[string]interface{})
+	expectedTemplate, _ := expected["template"].(map[string]interface{})
+	for _, key := range []string{"settings", "mappings", "aliases"} {
+		if !reflect.DeepEqual(currentTemplate[key], expectedTemplate[key]) {
+			diffs = append(diffs, "template."+key)
+		}
+	}
+
+	return diffs
+}
+
+// composableIndexTemplateMigrationJSON return the template JSON compared with the converted legacy template
+// The meta and the ownership marker in mappings are removed, they have no effect on indices
+func composableIndexTemplateMigrationJSON(template *ComposableIndexTemplateSpec) string {
+	result := *template
+	result.Meta = nil
+	if template.Template != nil && template.Template.Mappings != nil {
+		var mappings interface{}
+		if err := json.Unmarshal([]byte(convertInterfaceToJSONString(template.Template.Mappings)), &mappings); err == nil {
+			resultTemplate := *template.Template
+			resultTemplate.Mappings = canonical.Normalize(mappings, indexTemplateMigrationMappingsJSONRules)
+			result.Template = &resultTemplate
+		}
+	}
+
+	return convertInterfaceToJSONString(&result)
+}
+
+// resolveComposableIndexTemplate return the name of template with the highest priority matching index
+func resolveComposableIndexTemplate(index string, templates []*ComposableIndexTemplate) string {
+	resolved := ""
+	resolvedPriority := -1
+	for _, template := range templates {
+		if template.IndexTemplate.Priority <= resolvedPriority {
+			continue
+		}
+		for _, pattern := range template.IndexTemplate.IndexPatterns {
+			if indexPatternsOverlap(pattern, index) {
+				resolved = template.Name
+				resolvedPriority = template.IndexTemplate.Priority
+				break
+			}
+		}
+	}
+
+	return resolved
+}
+
+// composableIndexTemplateSimulateIndex return the index name used to simulate the template
+func composableIndexTemplateSimulateIndex(index string, template *ComposableIndexTemplateSpec) string {
+	if index != "" || len(template.IndexPatterns) == 0 {
+		return index
+	}
+
+	return strings.ReplaceAll(template.IndexPatterns[0], "*", "simulate")
+}
+
+// buildComposableIndexTemplate convert attributes to composable template object
+func buildComposableIndexTemplate(get func(string) interface{}) *ComposableIndexTemplateSpec {
+	template := &ComposableIndexTemplateSpec{
+		IndexPatterns: convertArrayInterfaceToArrayString(get("index_patterns").([]interface{})),
+		ComposedOf:    convertArrayInterfaceToArrayString(get("composed_of").([]interface{})),
+		Priority:      get("priority").(int),
+		Version:       get("version").(int),
+		Meta:          optionalInterfaceJSON(get("meta").(string)),
+	}
+
+	if raws := get("data_stream").([]interface{}); len(raws) > 0 {
+		template.DataStream = &ComposableIndexTemplateDataStream{}
+		if raws[0] != nil {
+			template.DataStream.Hidden = raws[0].(map[string]interface{})["hidden"].(bool)
+		}
+	}
+
+	if raws := get("template").([]interface{}); len(raws) > 0 && raws[0] != nil {
+		m := raws[0].(map[string]interface{})
+		template.Template = &ComposableIndexTemplateTemplate{
+			Settings: optionalInterfaceJSON(m["settings"].(string)),
+			Mappings: optionalInterfaceJSON(m["mappings"].(string)),
+			Aliases:  optionalInterfaceJSON(m["aliases"].(string)),
+		}
+	}
+
+	return template
+}
+
+// flattenComposableIndexTemplateDataStream convert data stream object to data_stream block
+func flattenComposableIndexTemplateDataStream(dataStream *ComposableIndexTemplateDataStream) []interface{} {
+	if dataStream == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"hidden": dataStream.Hidden,
+		},
+	}
+}
+
+// flattenComposableIndexTemplateTemplate convert template object to template block
+func flattenComposableIndexTemplateTemplate(template *ComposableIndexTemplateTemplate) []interface{} {
+	if template == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"settings": convertInterfaceToJSONString(template.Settings),
+			"mappings": convertInterfaceToJSONString(template.Mappings),
+			"aliases":  convertInterfaceToJSONString(template.Aliases),
+		},
+	}
+}
@@ -0,0 +1,513 @@
+package es
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/pkg/errors"
+)
+
+func TestAccElasticsearchComposableIndexTemplate(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchComposableIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchComposableIndexTemplate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_composable_index_template.test"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "resolved_template", "terraform-test"),
+					resource.TestCheckResourceAttrSet("elasticsearch_composable_index_template.test", "resolved_settings"),
+				),
+			},
+			{
+				Config: testElasticsearchComposableIndexTemplateSamePriority,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_composable_index_template.test"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "priority", "20"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "resolved_template", "terraform-test"),
+				),
+			},
+			{
+				Config: testElasticsearchComposableIndexTemplateUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_composable_index_template.test"),
+				),
+			},
+			{
+				ResourceName:            "elasticsearch_composable_index_template.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"resolved_template", "resolved_settings", "resolved_mappings", "overlapping_legacy_templates"},
+			},
+			{
+				Config:      testElasticsearchComposableIndexTemplatePriorityCollision,
+				ExpectError: regexp.MustCompile("with the same priority"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchComposableIndexTemplateCreateOnly(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchComposableIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchComposableIndexTemplate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_composable_index_template.test"),
+				),
+			},
+			{
+				Config:      testElasticsearchComposableIndexTemplateCreateOnly,
+				ExpectError: regexp.MustCompile("Composable index template terraform-test already exist"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchComposableIndexTemplateVerifyLegacyMigration(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchComposableIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchComposableIndexTemplateLegacy,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexTemplateExists("elasticsearch_index_template.legacy"),
+				),
+			},
+			{
+				Config:      testElasticsearchComposableIndexTemplateVerifyLegacyMigrationMismatch,
+				ExpectError: regexp.MustCompile("differ from legacy index template terraform-test-legacy on priority"),
+			},
+			{
+				Config: testElasticsearchComposableIndexTemplateVerifyLegacyMigration,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_composable_index_template.test"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "overlapping_legacy_templates.#", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "overlapping_legacy_templates.0", "terraform-test-legacy"),
+				),
+			},
+		},
+	})
+}
+
+func TestDiffComposableIndexTemplateMigration(t *testing.T) {
+	migration := &ComposableIndexTemplateSpec{
+		IndexPatterns: []string{"test*"},
+		Priority:      2,
+		Template: &ComposableIndexTemplateTemplate{
+			Settings: map[string]interface{}{
+				"index": map[string]interface{}{
+					"number_of_replicas": "0",
+				},
+			},
+			Aliases: map[string]interface{}{
+				"test-alias": map[string]interface{}{},
+			},
+		},
+	}
+
+	cases := []struct {
+		name     string
+		template *ComposableIndexTemplateSpec
+		expected []string
+	}{
+		{
+			name: "same content",
+			template: &ComposableIndexTemplateSpec{
+				IndexPatterns: []string{"test*"},
+				Priority:      2,
+				Meta:          json.RawMessage(`{"description":"migrated"}`),
+				Template: &ComposableIndexTemplateTemplate{
+					Settings: json.RawMessage(`{"number_of_replicas":0}`),
+					Mappings: json.RawMessage(`{"_meta":{"managed_by":{"team":"a"}}}`),
+					Aliases:  json.RawMessage(`{"test-alias":{}}`),
+				},
+			},
+			expected: []string{},
+		},
+		{
+			name: "different content",
+			template: &ComposableIndexTemplateSpec{
+				IndexPatterns: []string{"test*"},
+				ComposedOf:    []string{"component"},
+				Priority:      10,
+				DataStream:    &ComposableIndexTemplateDataStream{},
+				Template: &ComposableIndexTemplateTemplate{
+					Settings: json.RawMessage(`{"number_of_replicas":1}`),
+					Mappings: json.RawMessage(`{"properties":{"name":{"type":"keyword"}}}`),
+				},
+			},
+			expected: []string{"composed_of", "priority", "data_stream", "template.settings", "template.mappings", "template.aliases"},
+		},
+	}
+
+	for _, tc := range cases {
+		if actual := diffComposableIndexTemplateMigration(tc.template, migration); !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestAccElasticsearchDataStream(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchComposableIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchDataStreamTemplate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_xpack_data_stream_template.test"),
+				),
+			},
+			{
+				Config:   testElasticsearchDataStreamTemplate,
+				PlanOnly: true,
+			},
+			{
+				Config: testElasticsearchDataStreamTemplateUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_xpack_data_stream_template.test"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_xpack_data_stream_template.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestResourceElasticsearchDataStreamTemplateStateUpgradeV0(t *testing.T) {
+	// Template as stored by read before the template was unwrapped from get index template API response
+	rawState := map[string]interface{}{
+		"id":       "terraform-test",
+		"name":     "terraform-test",
+		"template": `{"index_templates":[{"name":"terraform-test","index_template":{"index_patterns":["test*"],"template":{"settings":{"index":{"lifecycle":{"name":"my-data-stream-policy"}}}},"composed_of":[],"priority":20,"data_stream":{}}}]}`,
+	}
+
+	actual, err := resourceElasticsearchDataStreamTemplateStateUpgradeV0(rawState, nil)
+	if err != nil {
+		t.Fatalf("Error when upgrade state: %s", err)
+	}
+	expected := `{"index_patterns":["test*"],"priority":20,"data_stream":{},"template":{"settings":{"index":{"lifecycle":{"name":"my-data-stream-policy"}}}}}`
+	if actual["template"] != expected {
+		t.Errorf("Expected %s, got %s", expected, actual["template"])
+	}
+
+	// The plan of the existing configuration on the upgraded state has no diff
+	r := resourceElasticsearchDataStreamTemplate()
+	d := schema.TestResourceDataRaw(t, r.Schema, map[string]interface{}{
+		"name":     actual["name"],
+		"template": actual["template"],
+	})
+	d.SetId("terraform-test")
+	state := d.State()
+
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"name":     "terraform-test",
+		"template": regexp.MustCompile("(?s)<<EOF\n(.*)EOF").FindStringSubmatch(testElasticsearchDataStreamTemplate)[1],
+	})
+	diff, err := schema.InternalMap(r.Schema).Diff(state, config, nil, nil, true)
+	if err != nil {
+		t.Fatalf("Error when diff upgraded state: %s", err)
+	}
+	if !diff.Empty() {
+		t.Errorf("Expected no diff on upgraded state, got %+v", diff)
+	}
+}
+
+func TestResourceElasticsearchComposableIndexTemplateStateUpgradeV0(t *testing.T) {
+	rawState := map[string]interface{}{
+		"id":   "terraform-test",
+		"name": "terraform-test",
+		"template": `{
+			"index_patterns": ["test*"],
+			"composed_of": ["component"],
+			"data_stream": {},
+			"priority": 20,
+			"template": {
+				"settings": {"index": {"lifecycle": {"name": "policy"}}}
+			}
+		}`,
+	}
+
+	expected := map[string]interface{}{
+		"id":             "terraform-test",
+		"name":           "terraform-test",
+		"index_patterns": []interface{}{"test*"},
+		"composed_of":    []interface{}{"component"},
+		"priority":       20,
+		"version":        0,
+		"data_stream": []interface{}{
+			map[string]interface{}{
+				"hidden": false,
+			},
+		},
+		"meta": "",
+		"template": []interface{}{
+			map[string]interface{}{
+				"settings": `{"index":{"lifecycle":{"name":"policy"}}}`,
+				"mappings": "",
+				"aliases":  "",
+			},
+		},
+	}
+
+	actual, err := resourceElasticsearchComposableIndexTemplateStateUpgradeV0(rawState, nil)
+	if err != nil {
+		t.Fatalf("Error when upgrade state: %s", err)
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %+v, got %+v", expected, actual)
+	}
+}
+
+func testCheckElasticsearchComposableIndexTemplateExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No composable index template ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		template, err := getComposableIndexTemplate(rs.Primary.ID, meta.(*providerConf).client)
+		if err != nil {
+			return err
+		}
+		if template == nil {
+			return errors.Errorf("Composable index template %s not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchComposableIndexTemplateDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_composable_index_template" && rs.Type != "elasticsearch_xpack_data_stream_template" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		template, err := getComposableIndexTemplate(rs.Primary.ID, meta.(*providerConf).client)
+		if err != nil {
+			return err
+		}
+		if template == nil {
+			return nil
+		}
+
+		return fmt.Errorf("Composable index template %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testElasticsearchComposableIndexTemplate = `
+resource "elasticsearch_composable_index_template" "test" {
+  name           = "terraform-test"
+  index_patterns = ["test*"]
+  priority       = 20
+
+  data_stream {}
+
+  template {
+    settings = jsonencode({
+      "index.lifecycle.name" = "my-data-stream-policy"
+      number_of_replicas     = 0
+    })
+    mappings = jsonencode({
+      properties = {
+        name = {
+          type = "keyword"
+        }
+      }
+    })
+  }
+}
+`
+
+var testElasticsearchComposableIndexTemplateSamePriority = `
+resource "elasticsearch_composable_index_template" "test" {
+  name           = "terraform-test"
+  index_patterns = ["test*"]
+  priority       = 20
+
+  data_stream {}
+
+  template {
+    settings = jsonencode({
+      "index.lifecycle.name" = "my-data-stream-policy"
+      number_of_replicas     = 1
+    })
+    mappings = jsonencode({
+      properties = {
+        name = {
+          type = "keyword"
+        }
+      }
+    })
+  }
+}
+`
+
+var testElasticsearchComposableIndexTemplateUpdate = `
+resource "elasticsearch_composable_index_template" "test" {
+  name           = "terraform-test"
+  index_patterns = ["test*"]
+  priority       = 23
+  version        = 2
+
+  data_stream {}
+
+  meta = jsonencode({
+    description = "managed by terraform"
+  })
+
+  template {
+    settings = jsonencode({
+      "index.lifecycle.name" = "my-data-stream-policy-2"
+      number_of_replicas     = 0
+    })
+  }
+}
+`
+
+var testElasticsearchComposableIndexTemplatePriorityCollision = testElasticsearchComposableIndexTemplateUpdate + `
+resource "elasticsearch_composable_index_template" "collision" {
+  name           = "terraform-test-collision"
+  index_patterns = ["test-*"]
+  priority       = 23
+
+  depends_on = [elasticsearch_composable_index_template.test]
+}
+`
+
+var testElasticsearchDataStreamTemplate = `
+resource "elasticsearch_xpack_data_stream_template" "test" {
+  name 		= "terraform-test"
+  template 	= <<EOF
+{
+  "index_patterns": [
+    "test*"
+  ],
+  "data_stream": {},
+  "template": {
+    "settings": {
+      "index.lifecycle.name": "my-data-stream-policy"
+    }
+  },
+  "priority": 20
+}
+EOF
+}
+`
+
+var testElasticsearchDataStreamTemplateUpdate = `
+resource "elasticsearch_xpack_data_stream_template" "test" {
+  name 		= "terraform-test"
+  template 	= <<EOF
+{
+  "index_patterns": [
+    "test*"
+  ],
+  "data_stream": {},
+  "template": {
+    "settings": {
+      "index.lifecycle.name": "my-data-stream-policy-2"
+    }
+  },
+  "priority": 23
+}
+EOF
+}
+`
+
+var testElasticsearchComposableIndexTemplateCreateOnly = testElasticsearchComposableIndexTemplate + `
+provider "elasticsearch" {
+  create_only = true
+}
+
+resource "elasticsearch_composable_index_template" "other" {
+  name           = "terraform-test"
+  index_patterns = ["other*"]
+  priority       = 30
+
+  depends_on = [elasticsearch_composable_index_template.test]
+}
+`
+
+var testElasticsearchComposableIndexTemplateLegacy = `
+resource "elasticsearch_index_template" "legacy" {
+  name     = "terraform-test-legacy"
+  template = jsonencode({
+    index_patterns = "test-legacy*"
+    order          = 2
+    settings = {
+      "index.refresh_interval" = "5s"
+    }
+  })
+}
+`
+
+var testElasticsearchComposableIndexTemplateVerifyLegacyMigrationMismatch = testElasticsearchComposableIndexTemplateLegacy + `
+resource "elasticsearch_composable_index_template" "test" {
+  name                    = "terraform-test-legacy"
+  index_patterns          = ["test-legacy*"]
+  priority                = 3
+  verify_legacy_migration = "terraform-test-legacy"
+
+  template {
+    settings = jsonencode({
+      "index.refresh_interval" = "5s"
+    })
+  }
+}
+`
+
+var testElasticsearchComposableIndexTemplateVerifyLegacyMigration = testElasticsearchComposableIndexTemplateLegacy + `
+resource "elasticsearch_composable_index_template" "test" {
+  name                    = "terraform-test-legacy"
+  index_patterns          = ["test-legacy*"]
+  priority                = 2
+  verify_legacy_migration = "terraform-test-legacy"
+
+  template {
+    settings = jsonencode({
+      "index.refresh_interval" = "5s"
+    })
+  }
+}
+`
@@ -11,14 +11,140 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"strings"
 
 	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+// indexLifecyclePhases is the list of phases in the order they are run
+var indexLifecyclePhases = []string{"hot", "warm", "cold", "frozen", "delete"}
+
+// indexLifecyclePhaseActions is the list of actions allowed by phase
+var indexLifecyclePhaseActions = map[string][]string{
+	"hot":    {"set_priority", "unfollow", "rollover", "readonly", "shrink", "forcemerge", "searchable_snapshot"},
+	"warm":   {"set_priority", "unfollow", "readonly", "allocate", "migrate", "shrink", "forcemerge"},
+	"cold":   {"set_priority", "unfollow", "readonly", "searchable_snapshot", "allocate", "migrate", "freeze"},
+	"frozen": {"unfollow", "searchable_snapshot"},
+	"delete": {"wait_for_snapshot", "delete"},
+}
+
+// IndexLifecyclePolicy object
+type IndexLifecyclePolicy struct {
+	Policy  *IndexLifecyclePolicySpec    `json:"policy"`
+	InUseBy *IndexLifecyclePolicyInUseBy `json:"in_use_by,omitempty"`
+}
+
+// IndexLifecyclePolicyInUseBy is the list of objects that use the policy
+type IndexLifecyclePolicyInUseBy struct {
+	Indices             []string `json:"indices"`
+	DataStreams         []string `json:"data_streams"`
+	ComposableTemplates []string `json:"composable_templates"`
+}
+
+// IndexLifecyclePolicySpec is the index lifecycle policy object
+type IndexLifecyclePolicySpec struct {
+	Phases map[string]*IndexLifecyclePolicyPhase `json:"phases"`
+}
+
+// IndexLifecyclePolicyPhase is the phase object
+type IndexLifecyclePolicyPhase struct {
+	MinAge  string                       `json:"min_age,omitempty"`
+	Actions *IndexLifecyclePolicyActions `json:"actions"`
+}
+
+// IndexLifecyclePolicyActions is the list of actions run in a phase
+type IndexLifecyclePolicyActions struct {
+	Rollover           *IndexLifecyclePolicyRollover           `json:"rollover,omitempty"`
+	Shrink             *IndexLifecyclePolicyShrink             `json:"shrink,omitempty"`
+	Forcemerge         *IndexLifecyclePolicyForcemerge         `json:"forcemerge,omitempty"`
+	Allocate           *IndexLifecyclePolicyAllocate           `json:"allocate,omitempty"`
+	SearchableSnapshot *IndexLifecyclePolicySearchableSnapshot `json:"searchable_snapshot,omitempty"`
+	SetPriority        *IndexLifecyclePolicySetPriority        `json:"set_priority,omitempty"`
+	Migrate            *IndexLifecyclePolicyMigrate            `json:"migrate,omitempty"`
+	WaitForSnapshot    *IndexLifecyclePolicyWaitForSnapshot    `json:"wait_for_snapshot,omitempty"`
+	Delete             *IndexLifecyclePolicyDelete             `json:"delete,omitempty"`
+	Readonly           *struct{}                               `json:"readonly,omitempty"`
+	Freeze             *struct{}                               `json:"freeze,omitempty"`
+	Unfollow           *struct{}                               `json:"unfollow,omitempty"`
+}
+
+// IndexLifecyclePolicyRollover is the rollover action
+type IndexLifecyclePolicyRollover struct {
+	MaxAge              string `json:"max_age,omitempty"`
+	MaxSize             string `json:"max_size,omitempty"`
+	MaxDocs             int    `json:"max_docs,omitempty"`
+	MaxPrimaryShardSize string `json:"max_primary_shard_size,omitempty"`
+}
+
+// IndexLifecyclePolicyShrink is the shrink action
+type IndexLifecyclePolicyShrink struct {
+	NumberOfShards      int    `json:"number_of_shards,omitempty"`
+	MaxPrimaryShardSize string `json:"max_primary_shard_size,omitempty"`
+}
+
+// IndexLifecyclePolicyForcemerge is the forcemerge action
+type IndexLifecyclePolicyForcemerge struct {
+	MaxNumSegments int    `json:"max_num_segments"`
+	IndexCodec     string `json:"index_codec,omitempty"`
+}
+
+// IndexLifecyclePolicyAllocate is the allocate action
+type IndexLifecyclePolicyAllocate struct {
+	NumberOfReplicas *int              `json:"number_of_replicas,omitempty"`
+	Include          map[string]string `json:"include,omitempty"`
+	Exclude          map[string]string `json:"exclude,omitempty"`
+	Require          map[string]string `json:"require,omitempty"`
+}
+
+// IndexLifecyclePolicySearchableSnapshot is the searchable_snapshot action
+type IndexLifecyclePolicySearchableSnapshot struct {
+	SnapshotRepository string `json:"snapshot_repository"`
+	ForceMergeIndex    *bool  `json:"force_merge_index,omitempty"`
+}
+
+// IndexLifecyclePolicySetPriority is the set_priority action
+type IndexLifecyclePolicySetPriority struct {
+	Priority int `json:"priority"`
+}
+
+// IndexLifecyclePolicyMigrate is the migrate action
+type IndexLifecyclePolicyMigrate struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// IndexLifecyclePolicyWaitForSnapshot is the wait_for_snapshot action
+type IndexLifecyclePolicyWaitForSnapshot struct {
+	Policy string `json:"policy"`
+}
+
+// IndexLifecyclePolicyDelete is the delete action
+type IndexLifecyclePolicyDelete struct {
+	DeleteSearchableSnapshot *bool `json:"delete_searchable_snapshot,omitempty"`
+}
+
+// indexLifecyclePolicyJSONRules are the defaults added by Elasticsearch on policy
+var indexLifecyclePolicyJSONRules = &canonical.Rules{
+	Defaults: map[string]interface{}{
+		"policy.phases.*.min_age": "0ms",
+		"policy.phases.delete.actions.delete.delete_searchable_snapshot": true,
+		"policy._meta": map[string]interface{}{},
+	},
+	ServerFields: []string{"policy._meta." + managedByKey},
+}
+
+// removeIndexLifecyclePolicyMaxPathLength is the max length of targets sent in one remove policy request,
+// below the 4kb default of http.max_initial_line_length
+const removeIndexLifecyclePolicyMaxPathLength = 3072
+
+// indexLifecyclePolicyManagedByPath is the path of object that store the ownership marker
+var indexLifecyclePolicyManagedByPath = []string{"policy", "_meta"}
+
 // resourceElasticsearchIndexLifecyclePolicy handle the index lifecycle policy API call
 func resourceElasticsearchIndexLifecyclePolicy() *schema.Resource {
 	return &schema.Resource{
@@ -27,8 +153,16 @@ func resourceElasticsearchIndexLifecyclePolicy() *schema.Resource {
 		Update: resourceElasticsearchIndexLifecyclePolicyUpdate,
 		Delete: resourceElasticsearchIndexLifecyclePolicyDelete,
 
+		CustomizeDiff: resourceElasticsearchIndexLifecyclePolicyCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: importStateManagedBy("Index lifecycle policy", func(id string, meta interface{}) (map[string]interface{}, error) {
+				policy, err := getIndexLifecyclePolicy(id, meta.(*providerConf).client)
+				if err != nil {
+					return nil, err
+				}
+				return getManagedBy(policy, indexLifecyclePolicyManagedByPath), nil
+			}),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -39,13 +173,326 @@ func resourceElasticsearchIndexLifecyclePolicy() *schema.Resource {
 			},
 			"policy": {
 				Type:             schema.TypeString,
-				Required:         true,
-				DiffSuppressFunc: suppressEquivalentJSON,
+				Optional:         true,
+				Computed:         true,
+				ExactlyOneOf:     []string{"policy", "phase"},
+				DiffSuppressFunc: suppressIndexLifecyclePolicy,
+				StateFunc:        canonicalJSONStateFunc(indexLifecyclePolicyJSONRules),
+			},
+			"phase": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{"policy", "phase"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(indexLifecyclePhases, false),
+						},
+						"min_age": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Computed:     true,
+							ValidateFunc: validateElasticsearchDuration,
+						},
+						"rollover": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"max_age": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validateElasticsearchDuration,
+									},
+									"max_size": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"max_docs": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										ValidateFunc: validation.IntAtLeast(1),
+									},
+									"max_primary_shard_size": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"shrink": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"number_of_shards": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										ValidateFunc: validation.IntAtLeast(1),
+									},
+									"max_primary_shard_size": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"forcemerge": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"max_num_segments": {
+										Type:         schema.TypeInt,
+										Required:     true,
+										ValidateFunc: validation.IntAtLeast(1),
+									},
+									"index_codec": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validation.StringInSlice([]string{"best_compression"}, false),
+									},
+								},
+							},
+						},
+						"allocate": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"number_of_replicas": {
+										Type:         schema.TypeInt,
+										Optional:     true,
+										Default:      -1,
+										Description:  "Number of replicas, -1 keep the current value",
+										ValidateFunc: validation.IntAtLeast(-1),
+									},
+									"include": {
+										Type:     schema.TypeMap,
+										Optional: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"exclude": {
+										Type:     schema.TypeMap,
+										Optional: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"require": {
+										Type:     schema.TypeMap,
+										Optional: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+								},
+							},
+						},
+						"searchable_snapshot": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"snapshot_repository": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"force_merge_index": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  true,
+									},
+								},
+							},
+						},
+						"set_priority": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"priority": {
+										Type:         schema.TypeInt,
+										Required:     true,
+										ValidateFunc: validation.IntAtLeast(0),
+									},
+								},
+							},
+						},
+						"migrate": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"enabled": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  true,
+									},
+								},
+							},
+						},
+						"wait_for_snapshot": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"policy": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
+						"delete": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"delete_searchable_snapshot": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										Default:  true,
+									},
+								},
+							},
+						},
+						"readonly": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"freeze": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"unfollow": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"force_detach": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Remove the policy from indices and data streams that use it before delete it. The delete always failed if composable templates still reference the policy",
+			},
+			"managed_by": managedBySchema(),
+			"takeover":   takeoverSchema(),
+			"in_use_by": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"indices": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"data_streams": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"composable_templates": {
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
 			},
 		},
 	}
 }
 
+// resourceElasticsearchIndexLifecyclePolicyCustomizeDiff check the phases and their actions at plan time
+// The policy is built from phases when they change, phases are read again when the policy change
+func resourceElasticsearchIndexLifecyclePolicyCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	phases := d.Get("phase").([]interface{})
+
+	lastPhaseIndex := -1
+	for _, raw := range phases {
+		if raw == nil {
+			continue
+		}
+		phase := raw.(map[string]interface{})
+		name := phase["name"].(string)
+
+		phaseIndex := -1
+		for i, phaseName := range indexLifecyclePhases {
+			if phaseName == name {
+				phaseIndex = i
+			}
+		}
+		if phaseIndex == -1 {
+			// Unknown value at plan time, it will be validated by the schema
+			continue
+		}
+		if phaseIndex <= lastPhaseIndex {
+			return errors.Errorf("Phase %s is declared more than once or not in the order %s", name, strings.Join(indexLifecyclePhases, ", "))
+		}
+		lastPhaseIndex = phaseIndex
+
+		actions := indexLifecyclePolicyPhaseActions(phase)
+		for _, action := range actions {
+			if !stringInSlice(action, indexLifecyclePhaseActions[name]) {
+				return errors.Errorf("Action %s is not allowed in phase %s, allowed actions are: %s", action, name, strings.Join(indexLifecyclePhaseActions[name], ", "))
+			}
+		}
+
+		if name == "hot" && !stringInSlice("rollover", actions) {
+			for _, action := range []string{"shrink", "forcemerge", "searchable_snapshot"} {
+				if stringInSlice(action, actions) {
+					return errors.Errorf("Action %s in phase hot requires the rollover action", action)
+				}
+			}
+		}
+	}
+
+	if d.HasChange("phase") && len(phases) > 0 {
+		if !d.NewValueKnown("phase") {
+			return d.SetNewComputed("policy")
+		}
+		b, err := json.Marshal(&IndexLifecyclePolicy{
+			Policy: buildIndexLifecyclePolicy(phases),
+		})
+		if err != nil {
+			return err
+		}
+		return d.SetNew("policy", canonicalJSONStateFunc(indexLifecyclePolicyJSONRules)(string(b)))
+	}
+	if d.HasChange("policy") {
+		return d.SetNewComputed("phase")
+	}
+
+	return nil
+}
+
 // resourceElasticsearchIndexLifecyclePolicyCreate create new index lifecycle policy
 func resourceElasticsearchIndexLifecyclePolicyCreate(d *schema.ResourceData, meta interface{}) error {
 	err := createIndexLifecyclePolicy(d, meta)
@@ -69,7 +516,7 @@ func resourceElasticsearchIndexLifecyclePolicyUpdate(d *schema.ResourceData, met
 func resourceElasticsearchIndexLifecyclePolicyRead(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.ILM.GetLifecycle(
 		client.API.ILM.GetLifecycle.WithContext(context.Background()),
 		client.API.ILM.GetLifecycle.WithPretty(),
@@ -104,16 +551,66 @@ func resourceElasticsearchIndexLifecyclePolicyRead(d *schema.ResourceData, meta
 
 	log.Debugf("Policy : %+v", policy)
 
+	indexLifecyclePolicies := make(map[string]*IndexLifecyclePolicy)
+	err = json.Unmarshal(b, &indexLifecyclePolicies)
+	if err != nil {
+		return err
+	}
+
 	d.Set("name", id)
-	d.Set("policy", policy)
+	d.Set("in_use_by", flattenIndexLifecyclePolicyInUseBy(indexLifecyclePolicies[id].InUseBy))
+	d.Set("managed_by", getManagedBy(policyTemp[id], indexLifecyclePolicyManagedByPath))
+
+	// Both forms are read, so that the policy is imported whatever the form used on config
+	b, err = json.Marshal(map[string]interface{}{
+		"policy": policy,
+	})
+	if err != nil {
+		return err
+	}
+	d.Set("policy", canonicalJSONStateFunc(indexLifecyclePolicyJSONRules)(string(b)))
+	d.Set("phase", flattenIndexLifecyclePolicyPhases(indexLifecyclePolicies[id].Policy))
+
 	return nil
 }
 
 // resourceElasticsearchIndexLifecyclePolicyDelete delete index lifecycle policy
+// It failed if the policy is still used, except if force_detach is enabled.
+// It always failed if the policy is referenced by composable templates
 func resourceElasticsearchIndexLifecyclePolicyDelete(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
+	forceDetach := d.Get("force_detach").(bool)
+
+	client := meta.(*providerConf).client
+
+	policy, err := getIndexLifecyclePolicy(id, client)
+	if err != nil {
+		return err
+	}
+	// in_use_by is not provided by all Elasticsearch versions
+	if policy != nil && policy["in_use_by"] != nil {
+		inUseBy := &IndexLifecyclePolicyInUseBy{}
+		if err := json.Unmarshal([]byte(convertInterfaceToJSONString(policy["in_use_by"])), inUseBy); err != nil {
+			return err
+		}
+
+		// New indices would be created with the deleted policy
+		if len(inUseBy.ComposableTemplates) > 0 {
+			return errors.Errorf("Index lifecycle policy %s is still referenced by composable templates [%s], remove the policy from their settings before delete it", id, strings.Join(inUseBy.ComposableTemplates, ", "))
+		}
+
+		targets := append(append([]string{}, inUseBy.Indices...), inUseBy.DataStreams...)
+		if len(targets) > 0 {
+			if !forceDetach {
+				return errors.Errorf("Index lifecycle policy %s is still in use by indices [%s] and data streams [%s], remove the policy from them or set force_detach = true", id, strings.Join(inUseBy.Indices, ", "), strings.Join(inUseBy.DataStreams, ", "))
+			}
+			if err = removeIndexLifecyclePolicy(targets, client); err != nil {
+				return err
+			}
+			log.Infof("Detached index lifecycle policy %s from %s", id, strings.Join(targets, ", "))
+		}
+	}
 
-	client := meta.(*elastic.Client)
 	res, err := client.API.ILM.DeleteLifecycle(
 		id,
 		client.API.ILM.DeleteLifecycle.WithContext(context.Background()),
@@ -145,7 +642,36 @@ func createIndexLifecyclePolicy(d *schema.ResourceData, meta interface{}) error
 	name := d.Get("name").(string)
 	policy := d.Get("policy").(string)
 
-	client := meta.(*elastic.Client)
+	// The policy is not known at plan time when phases are not
+	if phases := d.Get("phase").([]interface{}); policy == "" && len(phases) > 0 {
+		b, err := json.Marshal(&IndexLifecyclePolicy{
+			Policy: buildIndexLifecyclePolicy(phases),
+		})
+		if err != nil {
+			return err
+		}
+		policy = string(b)
+	}
+
+	client := meta.(*providerConf).client
+
+	current, err := getIndexLifecyclePolicy(name, client)
+	if err != nil {
+		return err
+	}
+	if current != nil && isCreateOnly(d, meta) {
+		return alreadyExistError("Index lifecycle policy", name)
+	}
+	if err := checkManagedBy("Index lifecycle policy", name, getManagedBy(current, indexLifecyclePolicyManagedByPath), d); err != nil {
+		return err
+	}
+	policy, err = stampManagedBy(policy, indexLifecyclePolicyManagedByPath, d.Get("managed_by").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Policy: %s", policy)
+
 	res, err := client.API.ILM.PutLifecycle(
 		name,
 		client.API.ILM.PutLifecycle.WithContext(context.Background()),
@@ -165,3 +691,334 @@ func createIndexLifecyclePolicy(d *schema.ResourceData, meta interface{}) error
 
 	return nil
 }
+
+// indexLifecyclePolicyPhaseActions return the list of actions set on phase block
+func indexLifecyclePolicyPhaseActions(phase map[string]interface{}) []string {
+	actions := make([]string, 0)
+	for key, value := range phase {
+		switch v := value.(type) {
+		case []interface{}:
+			if len(v) > 0 {
+				actions = append(actions, key)
+			}
+		case bool:
+			if v {
+				actions = append(actions, key)
+			}
+		}
+	}
+
+	return actions
+}
+
+// buildIndexLifecyclePolicy convert phase blocks to IndexLifecyclePolicySpec object
+func buildIndexLifecyclePolicy(raws []interface{}) *IndexLifecyclePolicySpec {
+	policy := &IndexLifecyclePolicySpec{
+		Phases: make(map[string]*IndexLifecyclePolicyPhase),
+	}
+
+	for _, raw := range raws {
+		m := raw.(map[string]interface{})
+		actions := &IndexLifecyclePolicyActions{}
+
+		if l := m["rollover"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			actions.Rollover = &IndexLifecyclePolicyRollover{
+				MaxAge:              a["max_age"].(string),
+				MaxSize:             a["max_size"].(string),
+				MaxDocs:             a["max_docs"].(int),
+				MaxPrimaryShardSize: a["max_primary_shard_size"].(string),
+			}
+		}
+		if l := m["shrink"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			actions.Shrink = &IndexLifecyclePolicyShrink{
+				NumberOfShards:      a["number_of_shards"].(int),
+				MaxPrimaryShardSize: a["max_primary_shard_size"].(string),
+			}
+		}
+		if l := m["forcemerge"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			actions.Forcemerge = &IndexLifecyclePolicyForcemerge{
+				MaxNumSegments: a["max_num_segments"].(int),
+				IndexCodec:     a["index_codec"].(string),
+			}
+		}
+		if l := m["allocate"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			actions.Allocate = &IndexLifecyclePolicyAllocate{
+				Include: convertMapInterfaceToMapString(a["include"].(map[string]interface{})),
+				Exclude: convertMapInterfaceToMapString(a["exclude"].(map[string]interface{})),
+				Require: convertMapInterfaceToMapString(a["require"].(map[string]interface{})),
+			}
+			if numberOfReplicas := a["number_of_replicas"].(int); numberOfReplicas >= 0 {
+				actions.Allocate.NumberOfReplicas = &numberOfReplicas
+			}
+		}
+		if l := m["searchable_snapshot"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			actions.SearchableSnapshot = &IndexLifecyclePolicySearchableSnapshot{
+				SnapshotRepository: a["snapshot_repository"].(string),
+			}
+			// Only send non default value to stay compatible with older versions
+			if forceMergeIndex := a["force_merge_index"].(bool); !forceMergeIndex {
+				actions.SearchableSnapshot.ForceMergeIndex = &forceMergeIndex
+			}
+		}
+		if l := m["set_priority"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			actions.SetPriority = &IndexLifecyclePolicySetPriority{
+				Priority: a["priority"].(int),
+			}
+		}
+		if l := m["migrate"].([]interface{}); len(l) > 0 {
+			actions.Migrate = &IndexLifecyclePolicyMigrate{}
+			if l[0] != nil {
+				if enabled := l[0].(map[string]interface{})["enabled"].(bool); !enabled {
+					actions.Migrate.Enabled = &enabled
+				}
+			}
+		}
+		if l := m["wait_for_snapshot"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			actions.WaitForSnapshot = &IndexLifecyclePolicyWaitForSnapshot{
+				Policy: a["policy"].(string),
+			}
+		}
+		if l := m["delete"].([]interface{}); len(l) > 0 {
+			actions.Delete = &IndexLifecyclePolicyDelete{}
+			if l[0] != nil {
+				if deleteSearchableSnapshot := l[0].(map[string]interface{})["delete_searchable_snapshot"].(bool); !deleteSearchableSnapshot {
+					actions.Delete.DeleteSearchableSnapshot = &deleteSearchableSnapshot
+				}
+			}
+		}
+		if m["readonly"].(bool) {
+			actions.Readonly = &struct{}{}
+		}
+		if m["freeze"].(bool) {
+			actions.Freeze = &struct{}{}
+		}
+		if m["unfollow"].(bool) {
+			actions.Unfollow = &struct{}{}
+		}
+
+		policy.Phases[m["name"].(string)] = &IndexLifecyclePolicyPhase{
+			MinAge:  m["min_age"].(string),
+			Actions: actions,
+		}
+	}
+
+	return policy
+}
+
+// flattenIndexLifecyclePolicyPhases convert IndexLifecyclePolicySpec object to phase blocks
+// Phases are always returned in the order they are run
+func flattenIndexLifecyclePolicyPhases(policy *IndexLifecyclePolicySpec) []interface{} {
+	phases := make([]interface{}, 0)
+	if policy == nil {
+		return phases
+	}
+
+	for _, name := range indexLifecyclePhases {
+		phase, ok := policy.Phases[name]
+		if !ok || phase == nil {
+			continue
+		}
+		actions := phase.Actions
+		if actions == nil {
+			actions = &IndexLifecyclePolicyActions{}
+		}
+
+		m := map[string]interface{}{
+			"name":                name,
+			"min_age":             phase.MinAge,
+			"rollover":            []interface{}{},
+			"shrink":              []interface{}{},
+			"forcemerge":          []interface{}{},
+			"allocate":            []interface{}{},
+			"searchable_snapshot": []interface{}{},
+			"set_priority":        []interface{}{},
+			"migrate":             []interface{}{},
+			"wait_for_snapshot":   []interface{}{},
+			"delete":              []interface{}{},
+			"readonly":            actions.Readonly != nil,
+			"freeze":              actions.Freeze != nil,
+			"unfollow":            actions.Unfollow != nil,
+		}
+
+		if a := actions.Rollover; a != nil {
+			m["rollover"] = []interface{}{map[string]interface{}{
+				"max_age":                a.MaxAge,
+				"max_size":               a.MaxSize,
+				"max_docs":               a.MaxDocs,
+				"max_primary_shard_size": a.MaxPrimaryShardSize,
+			}}
+		}
+		if a := actions.Shrink; a != nil {
+			m["shrink"] = []interface{}{map[string]interface{}{
+				"number_of_shards":       a.NumberOfShards,
+				"max_primary_shard_size": a.MaxPrimaryShardSize,
+			}}
+		}
+		if a := actions.Forcemerge; a != nil {
+			m["forcemerge"] = []interface{}{map[string]interface{}{
+				"max_num_segments": a.MaxNumSegments,
+				"index_codec":      a.IndexCodec,
+			}}
+		}
+		if a := actions.Allocate; a != nil {
+			numberOfReplicas := -1
+			if a.NumberOfReplicas != nil {
+				numberOfReplicas = *a.NumberOfReplicas
+			}
+			m["allocate"] = []interface{}{map[string]interface{}{
+				"number_of_replicas": numberOfReplicas,
+				"include":            a.Include,
+				"exclude":            a.Exclude,
+				"require":            a.Require,
+			}}
+		}
+		if a := actions.SearchableSnapshot; a != nil {
+			m["searchable_snapshot"] = []interface{}{map[string]interface{}{
+				"snapshot_repository": a.SnapshotRepository,
+				"force_merge_index":   a.ForceMergeIndex == nil || *a.ForceMergeIndex,
+			}}
+		}
+		if a := actions.SetPriority; a != nil {
+			m["set_priority"] = []interface{}{map[string]interface{}{
+				"priority": a.Priority,
+			}}
+		}
+		if a := actions.Migrate; a != nil {
+			m["migrate"] = []interface{}{map[string]interface{}{
+				"enabled": a.Enabled == nil || *a.Enabled,
+			}}
+		}
+		if a := actions.WaitForSnapshot; a != nil {
+			m["wait_for_snapshot"] = []interface{}{map[string]interface{}{
+				"policy": a.Policy,
+			}}
+		}
+		if a := actions.Delete; a != nil {
+			m["delete"] = []interface{}{map[string]interface{}{
+				"delete_searchable_snapshot": a.DeleteSearchableSnapshot == nil || *a.DeleteSearchableSnapshot,
+			}}
+		}
+
+		phases = append(phases, m)
+	}
+
+	return phases
+}
+
+// flattenIndexLifecyclePolicyInUseBy convert IndexLifecyclePolicyInUseBy object to in_use_by block
+func flattenIndexLifecyclePolicyInUseBy(inUseBy *IndexLifecyclePolicyInUseBy) []interface{} {
+	if inUseBy == nil {
+		return []interface{}{}
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"indices":              inUseBy.Indices,
+			"data_streams":         inUseBy.DataStreams,
+			"composable_templates": inUseBy.ComposableTemplates,
+		},
+	}
+}
+
+// getIndexLifecyclePolicy return the policy without the ID wrapper returned by the API
+// It return nil if policy not exist
+func getIndexLifecyclePolicy(id string, client *elastic.Client) (map[string]interface{}, error) {
+	res, err := client.API.ILM.GetLifecycle(
+		client.API.ILM.GetLifecycle.WithContext(context.Background()),
+		client.API.ILM.GetLifecycle.WithPretty(),
+		client.API.ILM.GetLifecycle.WithPolicy(id),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return nil, nil
+		}
+		return nil, errors.Errorf("Error when get lifecycle policy %s: %s", id, res.String())
+	}
+
+	indexLifecyclePolicies := make(map[string]map[string]interface{})
+	if err = json.NewDecoder(res.Body).Decode(&indexLifecyclePolicies); err != nil {
+		return nil, err
+	}
+
+	return indexLifecyclePolicies[id], nil
+}
+
+// removeIndexLifecyclePolicy remove the index lifecycle policy from indices or data streams
+// Targets are sent by batches to keep the request line under the Elasticsearch limit
+func removeIndexLifecyclePolicy(targets []string, client *elastic.Client) error {
+	for _, batch := range batchIndexTargets(targets, removeIndexLifecyclePolicyMaxPathLength) {
+		if err := removeIndexLifecyclePolicyFromTarget(strings.Join(batch, ","), client); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeIndexLifecyclePolicyFromTarget remove the index lifecycle policy from comma separated indices or data streams
+func removeIndexLifecyclePolicyFromTarget(target string, client *elastic.Client) error {
+	res, err := client.API.ILM.RemovePolicy(
+		target,
+		client.API.ILM.RemovePolicy.WithContext(context.Background()),
+		client.API.ILM.RemovePolicy.WithPretty(),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return errors.Errorf("Error when remove lifecycle policy from %s: %s", target, res.String())
+	}
+
+	data := make(map[string]interface{})
+	if err = json.NewDecoder(res.Body).Decode(&data); err != nil {
+		return err
+	}
+	if hasFailures, ok := data["has_failures"].(bool); ok && hasFailures {
+		return errors.Errorf("Error when remove lifecycle policy from %s: %v", target, data["failed_indexes"])
+	}
+
+	return nil
+}
+
+// batchIndexTargets split targets in batches whose comma separated and escaped list is not longer than maxLength
+// A target longer than maxLength is sent alone
+func batchIndexTargets(targets []string, maxLength int) [][]string {
+	batches := make([][]string, 0)
+	batch := make([]string, 0)
+	length := 0
+	for _, target := range targets {
+		targetLength := len(url.PathEscape(target))
+		if len(batch) > 0 && length+1+targetLength > maxLength {
+			batches = append(batches, batch)
+			batch = make([]string, 0)
+			length = 0
+		}
+		if len(batch) > 0 {
+			length++
+		}
+		batch = append(batch, target)
+		length += targetLength
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// suppressIndexLifecyclePolicy permit to compare policy in current state vs from API
+func suppressIndexLifecyclePolicy(k, old, new string, d *schema.ResourceData) bool {
+	return canonical.Equal(old, new, indexLifecyclePolicyJSONRules)
+}
@@ -2,11 +2,16 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/pkg/errors"
 )
@@ -33,10 +38,309 @@ func TestAccElasticsearchIndexLifecyclePolicy(t *testing.T) {
 				),
 			},
 			{
-				ResourceName:            "elasticsearch_index_lifecycle_policy.test",
-				ImportState:             true,
-				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"policy"},
+				ResourceName:      "elasticsearch_index_lifecycle_policy.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestBatchIndexTargets(t *testing.T) {
+	cases := []struct {
+		targets   []string
+		maxLength int
+		expected  [][]string
+	}{
+		{[]string{}, 10, [][]string{}},
+		{[]string{"a", "b", "c"}, 10, [][]string{{"a", "b", "c"}}},
+		{[]string{"aaa", "bbb", "ccc"}, 7, [][]string{{"aaa", "bbb"}, {"ccc"}}},
+		{[]string{"aaaaaaaaaa", "b"}, 5, [][]string{{"aaaaaaaaaa"}, {"b"}}},
+		{[]string{"a%b", "c"}, 6, [][]string{{"a%b"}, {"c"}}},
+	}
+
+	for _, tc := range cases {
+		if actual := batchIndexTargets(tc.targets, tc.maxLength); !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("Expected %v for %v with max length %d, got %v", tc.expected, tc.targets, tc.maxLength, actual)
+		}
+	}
+}
+
+func TestBuildIndexLifecyclePolicy(t *testing.T) {
+	cases := []struct {
+		name     string
+		phases   []interface{}
+		expected string
+	}{
+		{
+			name:     "no phase",
+			phases:   []interface{}{},
+			expected: `{"phases": {}}`,
+		},
+		{
+			name: "hot phase",
+			phases: []interface{}{
+				testIndexLifecyclePolicyPhaseBlock("hot", "0ms", map[string]interface{}{
+					"rollover":     []interface{}{map[string]interface{}{"max_age": "1d", "max_size": "", "max_docs": 0, "max_primary_shard_size": "50gb"}},
+					"set_priority": []interface{}{map[string]interface{}{"priority": 100}},
+					"forcemerge":   []interface{}{map[string]interface{}{"max_num_segments": 1, "index_codec": "best_compression"}},
+				}),
+			},
+			expected: `{"phases": {"hot": {"min_age": "0ms", "actions": {
+				"rollover": {"max_age": "1d", "max_primary_shard_size": "50gb"},
+				"set_priority": {"priority": 100},
+				"forcemerge": {"max_num_segments": 1, "index_codec": "best_compression"}
+			}}}}`,
+		},
+		{
+			name: "warm and cold phases",
+			phases: []interface{}{
+				testIndexLifecyclePolicyPhaseBlock("warm", "7d", map[string]interface{}{
+					"allocate": []interface{}{map[string]interface{}{
+						"number_of_replicas": -1,
+						"include":            map[string]interface{}{"box": "warm"},
+						"exclude":            map[string]interface{}{},
+						"require":            map[string]interface{}{},
+					}},
+					"migrate":  []interface{}{map[string]interface{}{"enabled": false}},
+					"readonly": true,
+				}),
+				testIndexLifecyclePolicyPhaseBlock("cold", "30d", map[string]interface{}{
+					"allocate": []interface{}{map[string]interface{}{
+						"number_of_replicas": 0,
+						"include":            map[string]interface{}{},
+						"exclude":            map[string]interface{}{},
+						"require":            map[string]interface{}{"box": "cold"},
+					}},
+					"searchable_snapshot": []interface{}{map[string]interface{}{"snapshot_repository": "repo", "force_merge_index": false}},
+					"freeze":              true,
+				}),
+			},
+			expected: `{"phases": {
+				"warm": {"min_age": "7d", "actions": {"allocate": {"include": {"box": "warm"}}, "migrate": {"enabled": false}, "readonly": {}}},
+				"cold": {"min_age": "30d", "actions": {"allocate": {"number_of_replicas": 0, "require": {"box": "cold"}}, "searchable_snapshot": {"snapshot_repository": "repo", "force_merge_index": false}, "freeze": {}}}
+			}}`,
+		},
+		{
+			name: "delete phase with default values",
+			phases: []interface{}{
+				testIndexLifecyclePolicyPhaseBlock("delete", "90d", map[string]interface{}{
+					"wait_for_snapshot": []interface{}{map[string]interface{}{"policy": "daily"}},
+					"delete":            []interface{}{map[string]interface{}{"delete_searchable_snapshot": true}},
+					"migrate":           []interface{}{nil},
+				}),
+			},
+			expected: `{"phases": {"delete": {"min_age": "90d", "actions": {"wait_for_snapshot": {"policy": "daily"}, "delete": {}, "migrate": {}}}}}`,
+		},
+	}
+
+	for _, c := range cases {
+		b, err := json.Marshal(buildIndexLifecyclePolicy(c.phases))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !canonical.Equal(c.expected, string(b), nil) {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, string(b))
+		}
+	}
+}
+
+func TestFlattenIndexLifecyclePolicyPhases(t *testing.T) {
+	cases := []struct {
+		name     string
+		policy   string
+		expected []interface{}
+	}{
+		{
+			name:     "no phase",
+			policy:   `{"phases": {}}`,
+			expected: []interface{}{},
+		},
+		{
+			name:   "phases in the order they are run",
+			policy: `{"phases": {"delete": {"min_age": "30d", "actions": {"delete": {}}}, "hot": {"min_age": "0ms", "actions": {"rollover": {"max_age": "1d"}}}}}`,
+			expected: []interface{}{
+				testIndexLifecyclePolicyPhaseBlock("hot", "0ms", map[string]interface{}{
+					"rollover": []interface{}{map[string]interface{}{"max_age": "1d", "max_size": "", "max_docs": 0, "max_primary_shard_size": ""}},
+				}),
+				testIndexLifecyclePolicyPhaseBlock("delete", "30d", map[string]interface{}{
+					"delete": []interface{}{map[string]interface{}{"delete_searchable_snapshot": true}},
+				}),
+			},
+		},
+		{
+			name:   "warm phase",
+			policy: `{"phases": {"warm": {"min_age": "7d", "actions": {"allocate": {"number_of_replicas": 0, "require": {"box": "warm"}}, "migrate": {"enabled": false}, "readonly": {}, "shrink": {"number_of_shards": 1}}}}}`,
+			expected: []interface{}{
+				testIndexLifecyclePolicyPhaseBlock("warm", "7d", map[string]interface{}{
+					"allocate": []interface{}{map[string]interface{}{
+						"number_of_replicas": 0,
+						"include":            map[string]string(nil),
+						"exclude":            map[string]string(nil),
+						"require":            map[string]string{"box": "warm"},
+					}},
+					"migrate":  []interface{}{map[string]interface{}{"enabled": false}},
+					"shrink":   []interface{}{map[string]interface{}{"number_of_shards": 1, "max_primary_shard_size": ""}},
+					"readonly": true,
+				}),
+			},
+		},
+		{
+			name:   "cold phase with default values",
+			policy: `{"phases": {"cold": {"min_age": "30d", "actions": {"allocate": {"include": {"box": "cold"}}, "searchable_snapshot": {"snapshot_repository": "repo"}, "set_priority": {"priority": 0}}}}}`,
+			expected: []interface{}{
+				testIndexLifecyclePolicyPhaseBlock("cold", "30d", map[string]interface{}{
+					"allocate": []interface{}{map[string]interface{}{
+						"number_of_replicas": -1,
+						"include":            map[string]string{"box": "cold"},
+						"exclude":            map[string]string(nil),
+						"require":            map[string]string(nil),
+					}},
+					"searchable_snapshot": []interface{}{map[string]interface{}{"snapshot_repository": "repo", "force_merge_index": true}},
+					"set_priority":        []interface{}{map[string]interface{}{"priority": 0}},
+				}),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		policy := &IndexLifecyclePolicySpec{}
+		if err := json.Unmarshal([]byte(c.policy), policy); err != nil {
+			t.Fatal(err)
+		}
+		if actual := flattenIndexLifecyclePolicyPhases(policy); !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, actual)
+		}
+	}
+
+	if actual := flattenIndexLifecyclePolicyPhases(nil); !reflect.DeepEqual([]interface{}{}, actual) {
+		t.Errorf("Expected no phase for nil policy, got %+v", actual)
+	}
+}
+
+func TestResourceElasticsearchIndexLifecyclePolicyCustomizeDiff(t *testing.T) {
+	cases := []struct {
+		name   string
+		phases []interface{}
+		err    string
+	}{
+		{
+			name: "valid phases",
+			phases: []interface{}{
+				map[string]interface{}{"name": "hot", "rollover": []interface{}{map[string]interface{}{"max_age": "1d"}}, "shrink": []interface{}{map[string]interface{}{"number_of_shards": 1}}},
+				map[string]interface{}{"name": "warm", "allocate": []interface{}{map[string]interface{}{"number_of_replicas": 1}}, "readonly": true},
+				map[string]interface{}{"name": "delete", "min_age": "30d", "delete": []interface{}{map[string]interface{}{}}},
+			},
+		},
+		{
+			name: "invalid block action",
+			phases: []interface{}{
+				map[string]interface{}{"name": "hot", "allocate": []interface{}{map[string]interface{}{"number_of_replicas": 1}}},
+			},
+			err: "Action allocate is not allowed in phase hot",
+		},
+		{
+			name: "invalid boolean action",
+			phases: []interface{}{
+				map[string]interface{}{"name": "delete", "freeze": true},
+			},
+			err: "Action freeze is not allowed in phase delete",
+		},
+		{
+			name: "phases not in order",
+			phases: []interface{}{
+				map[string]interface{}{"name": "warm", "readonly": true},
+				map[string]interface{}{"name": "hot", "set_priority": []interface{}{map[string]interface{}{"priority": 100}}},
+			},
+			err: "Phase hot is declared more than once or not in the order",
+		},
+		{
+			name: "duplicated phase",
+			phases: []interface{}{
+				map[string]interface{}{"name": "warm", "readonly": true},
+				map[string]interface{}{"name": "warm", "freeze": false},
+			},
+			err: "Phase warm is declared more than once",
+		},
+		{
+			name: "hot action without rollover",
+			phases: []interface{}{
+				map[string]interface{}{"name": "hot", "forcemerge": []interface{}{map[string]interface{}{"max_num_segments": 1}}},
+			},
+			err: "Action forcemerge in phase hot requires the rollover action",
+		},
+	}
+
+	resourceSchema := resourceElasticsearchIndexLifecyclePolicy().Schema
+	for _, c := range cases {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"name":  "terraform-test",
+			"phase": c.phases,
+		})
+		diff, err := schema.InternalMap(resourceSchema).Diff(nil, config, resourceElasticsearchIndexLifecyclePolicyCustomizeDiff, nil, true)
+		if c.err == "" && err != nil {
+			t.Errorf("%s: expected no error, got %s", c.name, err)
+		}
+		// The policy put is built from phases
+		if c.err == "" && err == nil && !strings.Contains(diff.Attributes["policy"].New, `"phases":{"delete"`) {
+			t.Errorf("%s: expected policy built from phases, got %+v", c.name, diff.Attributes["policy"])
+		}
+		if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("%s: expected error %q, got %v", c.name, c.err, err)
+		}
+	}
+}
+
+// testIndexLifecyclePolicyPhaseBlock return a phase block without action, except the ones provided
+func testIndexLifecyclePolicyPhaseBlock(name string, minAge string, actions map[string]interface{}) map[string]interface{} {
+	phase := map[string]interface{}{
+		"name":                name,
+		"min_age":             minAge,
+		"rollover":            []interface{}{},
+		"shrink":              []interface{}{},
+		"forcemerge":          []interface{}{},
+		"allocate":            []interface{}{},
+		"searchable_snapshot": []interface{}{},
+		"set_priority":        []interface{}{},
+		"migrate":             []interface{}{},
+		"wait_for_snapshot":   []interface{}{},
+		"delete":              []interface{}{},
+		"readonly":            false,
+		"freeze":              false,
+		"unfollow":            false,
+	}
+	for action, value := range actions {
+		phase[action] = value
+	}
+
+	return phase
+}
+
+func TestAccElasticsearchIndexLifecyclePolicyPhases(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIndexLifecyclePolicyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testElasticsearchIndexLifecyclePolicyPhasesInvalidAction,
+				ExpectError: regexp.MustCompile("Action allocate is not allowed in phase hot"),
+			},
+			{
+				Config: testElasticsearchIndexLifecyclePolicyPhases,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexLifecyclePolicyExists("elasticsearch_index_lifecycle_policy.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index_lifecycle_policy.test", "phase.0.name", "hot"),
+					resource.TestCheckResourceAttr("elasticsearch_index_lifecycle_policy.test", "phase.1.forcemerge.0.max_num_segments", "1"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_index_lifecycle_policy.test",
+				ImportState:       true,
+				ImportStateVerify: true,
 			},
 		},
 	})
@@ -54,7 +358,7 @@ func testCheckElasticsearchIndexLifecyclePolicyExists(name string) resource.Test
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.ILM.GetLifecycle(
 			client.API.ILM.GetLifecycle.WithContext(context.Background()),
 			client.API.ILM.GetLifecycle.WithPretty(),
@@ -80,7 +384,7 @@ func testCheckElasticsearchIndexLifecyclePolicyDestroy(s *terraform.State) error
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.ILM.GetLifecycle(
 			client.API.ILM.GetLifecycle.WithContext(context.Background()),
 			client.API.ILM.GetLifecycle.WithPretty(),
@@ -157,3 +461,54 @@ resource "elasticsearch_index_lifecycle_policy" "test" {
 EOF
 }
 `
+
+var testElasticsearchIndexLifecyclePolicyPhases = `
+resource "elasticsearch_index_lifecycle_policy" "test" {
+  name = "terraform-test"
+
+  phase {
+    name = "hot"
+    rollover {
+      max_age  = "1d"
+      max_size = "50gb"
+    }
+    set_priority {
+      priority = 100
+    }
+  }
+
+  phase {
+    name    = "warm"
+    min_age = "10d"
+    forcemerge {
+      max_num_segments = 1
+    }
+    allocate {
+      number_of_replicas = 0
+      require = {
+        "box_type" = "warm"
+      }
+    }
+    readonly = true
+  }
+
+  phase {
+    name    = "delete"
+    min_age = "30d"
+    delete {}
+  }
+}
+`
+
+var testElasticsearchIndexLifecyclePolicyPhasesInvalidAction = `
+resource "elasticsearch_index_lifecycle_policy" "test" {
+  name = "terraform-test"
+
+  phase {
+    name = "hot"
+    allocate {
+      number_of_replicas = 1
+    }
+  }
+}
+`
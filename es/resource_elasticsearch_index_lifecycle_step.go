@@ -0,0 +1,292 @@
+// Move index to a lifecycle step or retry the failed step in Elasticsearch
+// API documentation:
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-move-to-step.html
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-retry-policy.html
+// Supported version:
+//  - v7
+
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// IndexLifecycleMoveToStep is the move to step object
+type IndexLifecycleMoveToStep struct {
+	CurrentStep *IndexLifecycleStepKey `json:"current_step"`
+	NextStep    *IndexLifecycleStepKey `json:"next_step"`
+}
+
+// IndexLifecycleStepKey identify a lifecycle step
+type IndexLifecycleStepKey struct {
+	Phase  string `json:"phase"`
+	Action string `json:"action,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// resourceElasticsearchIndexLifecycleStep handle the ILM move to step and retry API call
+// It's an action: all attributes force a new resource and delete only remove it from state
+func resourceElasticsearchIndexLifecycleStep() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceElasticsearchIndexLifecycleStepCreate,
+		Read:   resourceElasticsearchIndexLifecycleStepRead,
+		Delete: resourceElasticsearchIndexLifecycleStepDelete,
+
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(1 * time.Minute),
+		},
+
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"retry": {
+				Type:         schema.TypeBool,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"retry", "next_step"},
+				ValidateFunc: validateTrue,
+				Description:  "Retry the failed step instead of move to next_step, it can only be set to true",
+			},
+			"next_step": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				ForceNew:     true,
+				MaxItems:     1,
+				ExactlyOneOf: []string{"retry", "next_step"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"phase": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringInSlice(indexLifecyclePhases, false),
+						},
+						"action": {
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"name": {
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+					},
+				},
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Arbitrary values that run again the step when they change",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"phase": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"action": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"step": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+// resourceElasticsearchIndexLifecycleStepCreate move the index to step or retry it, then wait it lands on the expected step
+func resourceElasticsearchIndexLifecycleStepCreate(d *schema.ResourceData, meta interface{}) error {
+	index := d.Get("index").(string)
+	client := meta.(*providerConf).client
+
+	var expectedStep *IndexLifecycleStepKey
+	if d.Get("retry").(bool) {
+		if err := retryIndexLifecycleStep(index, client); err != nil {
+			return err
+		}
+	} else {
+		expectedStep = buildIndexLifecycleStepKey(d.Get("next_step").([]interface{}))
+		if err := moveIndexLifecycleStep(index, expectedStep, client); err != nil {
+			return err
+		}
+	}
+
+	err := resource.Retry(d.Timeout(schema.TimeoutCreate), func() *resource.RetryError {
+		explainIndex, err := getIndexLifecycleExplainIndex(index, client)
+		if err != nil {
+			return resource.NonRetryableError(err)
+		}
+		if explainIndex == nil {
+			return resource.NonRetryableError(errors.Errorf("Index %s not found", index))
+		}
+		if explainIndex.Step == "ERROR" {
+			return resource.NonRetryableError(errors.Errorf("Index %s is on ERROR after failed step %s: %s", index, explainIndex.FailedStep, flattenIndexLifecycleExplainStepInfo(explainIndex.StepInfo)))
+		}
+		if expectedStep != nil && !indexLifecycleStepReached(explainIndex, expectedStep) {
+			return resource.RetryableError(errors.Errorf("Index %s is on step %s/%s/%s instead of %s/%s/%s", index, explainIndex.Phase, explainIndex.Action, explainIndex.Step, expectedStep.Phase, expectedStep.Action, expectedStep.Name))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.SetId(index)
+
+	log.Infof("Index %s lifecycle step handled successfully", index)
+
+	return resourceElasticsearchIndexLifecycleStepRead(d, meta)
+}
+
+// resourceElasticsearchIndexLifecycleStepRead read the current step of index
+func resourceElasticsearchIndexLifecycleStepRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	explainIndex, err := getIndexLifecycleExplainIndex(id, meta.(*providerConf).client)
+	if err != nil {
+		return err
+	}
+	if explainIndex == nil {
+		fmt.Printf("[WARN] Index %s not found - removing from state", id)
+		log.Warnf("Index %s not found - removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("index", id)
+	d.Set("phase", explainIndex.Phase)
+	d.Set("action", explainIndex.Action)
+	d.Set("step", explainIndex.Step)
+
+	return nil
+}
+
+// resourceElasticsearchIndexLifecycleStepDelete only remove the step from state
+func resourceElasticsearchIndexLifecycleStepDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
+
+// getIndexLifecycleExplainIndex return the lifecycle state of one index
+// It return nil if the index not exist
+func getIndexLifecycleExplainIndex(index string, client *elastic.Client) (*IndexLifecycleExplainIndex, error) {
+	explain, err := explainIndexLifecycle(index, false, false, client)
+	if err != nil {
+		return nil, err
+	}
+	if explain == nil || explain.Indices[index] == nil {
+		return nil, nil
+	}
+
+	return explain.Indices[index], nil
+}
+
+// moveIndexLifecycleStep move index from its current step to the next step
+func moveIndexLifecycleStep(index string, nextStep *IndexLifecycleStepKey, client *elastic.Client) error {
+	explainIndex, err := getIndexLifecycleExplainIndex(index, client)
+	if err != nil {
+		return err
+	}
+	if explainIndex == nil {
+		return errors.Errorf("Index %s not found", index)
+	}
+	if !explainIndex.Managed {
+		return errors.Errorf("Index %s is not managed by index lifecycle", index)
+	}
+
+	currentStep := &IndexLifecycleStepKey{
+		Phase:  explainIndex.Phase,
+		Action: explainIndex.Action,
+		Name:   explainIndex.Step,
+	}
+
+	b, err := json.Marshal(&IndexLifecycleMoveToStep{
+		CurrentStep: currentStep,
+		NextStep:    nextStep,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Move index %s to step: %s", index, string(b))
+
+	res, err := client.API.ILM.MoveToStep(
+		index,
+		client.API.ILM.MoveToStep.WithBody(bytes.NewReader(b)),
+		client.API.ILM.MoveToStep.WithContext(context.Background()),
+		client.API.ILM.MoveToStep.WithPretty(),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return errors.Errorf("Error when move index %s to step: %s", index, res.String())
+	}
+
+	return nil
+}
+
+// retryIndexLifecycleStep retry the failed step of index
+func retryIndexLifecycleStep(index string, client *elastic.Client) error {
+	res, err := client.API.ILM.Retry(
+		index,
+		client.API.ILM.Retry.WithContext(context.Background()),
+		client.API.ILM.Retry.WithPretty(),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return errors.Errorf("Error when retry lifecycle step of index %s: %s", index, res.String())
+	}
+
+	return nil
+}
+
+// indexLifecycleStepReached check if index is on the expected step
+// Action and name are only checked when they are provided
+func indexLifecycleStepReached(explainIndex *IndexLifecycleExplainIndex, expectedStep *IndexLifecycleStepKey) bool {
+	if explainIndex.Phase != expectedStep.Phase {
+		return false
+	}
+	if expectedStep.Action != "" && explainIndex.Action != expectedStep.Action {
+		return false
+	}
+	if expectedStep.Name != "" && explainIndex.Step != expectedStep.Name {
+		return false
+	}
+
+	return true
+}
+
+// buildIndexLifecycleStepKey convert next_step block to IndexLifecycleStepKey object
+func buildIndexLifecycleStepKey(raws []interface{}) *IndexLifecycleStepKey {
+	m := raws[0].(map[string]interface{})
+
+	return &IndexLifecycleStepKey{
+		Phase:  m["phase"].(string),
+		Action: m["action"].(string),
+		Name:   m["name"].(string),
+	}
+}
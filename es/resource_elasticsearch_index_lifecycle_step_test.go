@@ -0,0 +1,145 @@
+package es
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/pkg/errors"
+)
+
+func TestAccElasticsearchIndexLifecycleStep(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIndexLifecycleStepDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testElasticsearchIndexLifecycleStepMissingIndex,
+				ExpectError: regexp.MustCompile("Index terraform-test-missing not found"),
+			},
+			{
+				Config:      testElasticsearchIndexLifecycleStepRetryFalse,
+				ExpectError: regexp.MustCompile("expected retry to be true"),
+			},
+			{
+				Config: testElasticsearchIndexLifecycleStepPolicy,
+			},
+			{
+				PreConfig: testCreateElasticsearchIndexLifecycleStepIndex(t, "terraform-test-step"),
+				Config:    testElasticsearchIndexLifecycleStep,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index_lifecycle_step.test", "phase", "warm"),
+					resource.TestCheckResourceAttr("elasticsearch_index_lifecycle_step.test", "action", "allocate"),
+				),
+			},
+		},
+	})
+}
+
+// testCreateElasticsearchIndexLifecycleStepIndex create index managed by the test policy and wait it's initialized by ILM
+func testCreateElasticsearchIndexLifecycleStepIndex(t *testing.T, name string) func() {
+	return func() {
+		client := testAccProvider.Meta().(*providerConf).client
+		res, err := client.Indices.Create(
+			name,
+			client.Indices.Create.WithBody(strings.NewReader(`{"settings": {"index.number_of_replicas": 0, "index.lifecycle.name": "terraform-test-step"}}`)),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			t.Fatalf("Error when create index %s: %s", name, res.String())
+		}
+
+		for i := 0; i < 30; i++ {
+			explainIndex, err := getIndexLifecycleExplainIndex(name, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if explainIndex != nil && explainIndex.Phase != "" && explainIndex.Step == "complete" {
+				return
+			}
+			time.Sleep(1 * time.Second)
+		}
+		t.Fatalf("Index %s is not initialized by index lifecycle", name)
+	}
+}
+
+// testCheckElasticsearchIndexLifecycleStepDestroy delete the index used to move step, the step itself has nothing to destroy
+func testCheckElasticsearchIndexLifecycleStepDestroy(s *terraform.State) error {
+	client := testAccProvider.Meta().(*providerConf).client
+	res, err := client.Indices.Delete(
+		[]string{"terraform-test-step"},
+		client.Indices.Delete.WithIgnoreUnavailable(true),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return errors.Errorf("Error when delete index terraform-test-step: %s", res.String())
+	}
+
+	return nil
+}
+
+var testElasticsearchIndexLifecycleStepMissingIndex = `
+resource "elasticsearch_index_lifecycle_step" "test" {
+  index = "terraform-test-missing"
+  next_step {
+    phase  = "warm"
+    action = "forcemerge"
+  }
+}
+`
+
+var testElasticsearchIndexLifecycleStepRetryFalse = `
+resource "elasticsearch_index_lifecycle_step" "test" {
+  index = "terraform-test-missing"
+  retry = false
+}
+`
+
+var testElasticsearchIndexLifecycleStepPolicy = `
+resource "elasticsearch_index_lifecycle_policy" "test" {
+  name         = "terraform-test-step"
+  force_detach = true
+  policy       = <<EOF
+{
+  "policy": {
+    "phases": {
+      "warm": {
+        "min_age": "10d",
+        "actions": {
+          "allocate": {
+            "require": {
+              "box_type": "terraform-test-missing"
+            }
+          }
+        }
+      }
+    }
+  }
+}
+EOF
+}
+`
+
+var testElasticsearchIndexLifecycleStep = testElasticsearchIndexLifecycleStepPolicy + `
+resource "elasticsearch_index_lifecycle_step" "test" {
+  index = "terraform-test-step"
+  next_step {
+    phase  = "warm"
+    action = "allocate"
+    name   = "allocate"
+  }
+}
+`
@@ -8,16 +8,68 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strings"
 
 	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+// indexTemplateJSONRules are the defaults added by Elasticsearch on legacy index template
+var indexTemplateJSONRules = &canonical.Rules{
+	Defaults: map[string]interface{}{
+		"order":          0,
+		"settings":       map[string]interface{}{},
+		"mappings":       map[string]interface{}{},
+		"mappings._meta": map[string]interface{}{},
+		"aliases":        map[string]interface{}{},
+	},
+	ServerFields: []string{"mappings._meta." + managedByKey},
+}
+
+// indexTemplateManagedByPath is the path of object that store the ownership marker
+var indexTemplateManagedByPath = []string{"mappings", "_meta"}
+
+// IndexTemplateLegacy is the get legacy index template API response
+type IndexTemplateLegacy map[string]*IndexTemplateLegacySpec
+
+// IndexTemplateLegacySpec is the legacy index template object
+type IndexTemplateLegacySpec struct {
+	IndexPatterns IndexPatterns `json:"index_patterns"`
+	Order         int           `json:"order,omitempty"`
+	Version       int           `json:"version,omitempty"`
+	Settings      interface{}   `json:"settings,omitempty"`
+	Mappings      interface{}   `json:"mappings,omitempty"`
+	Aliases       interface{}   `json:"aliases,omitempty"`
+}
+
+// IndexPatterns is the list of index patterns of legacy template, a single pattern can be provided as string
+type IndexPatterns []string
+
+// UnmarshalJSON read index patterns provided as list or as string
+func (p *IndexPatterns) UnmarshalJSON(data []byte) error {
+	var pattern string
+	if err := json.Unmarshal(data, &pattern); err == nil {
+		*p = IndexPatterns{pattern}
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(data, &patterns); err != nil {
+		return err
+	}
+	*p = IndexPatterns(patterns)
+
+	return nil
+}
+
 // resourceElasticsearchIndexTemplate handle the index template API call
 func resourceElasticsearchIndexTemplate() *schema.Resource {
 	return &schema.Resource{
@@ -26,8 +78,16 @@ func resourceElasticsearchIndexTemplate() *schema.Resource {
 		Read:   resourceElasticsearchIndexTemplateRead,
 		Delete: resourceElasticsearchIndexTemplateDelete,
 
+		CustomizeDiff: resourceElasticsearchIndexTemplateCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: importStateManagedBy("Index template", func(id string, meta interface{}) (map[string]interface{}, error) {
+				templates, err := getIndexTemplatesLegacy(id, meta.(*providerConf).client)
+				if err != nil || templates[id] == nil {
+					return nil, err
+				}
+				return getManagedBy(templates[id].Mappings, []string{"_meta"}), nil
+			}),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -41,6 +101,16 @@ func resourceElasticsearchIndexTemplate() *schema.Resource {
 				Required:         true,
 				DiffSuppressFunc: diffSuppressIndexTemplate,
 			},
+			"overlapping_composable_templates": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Composable templates with overlapping index patterns, this template is ignored for indices matching them",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"managed_by": managedBySchema(),
+			"takeover":   takeoverSchema(),
 		},
 	}
 }
@@ -69,7 +139,7 @@ func resourceElasticsearchIndexTemplateUpdate(d *schema.ResourceData, meta inter
 func resourceElasticsearchIndexTemplateRead(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Indices.GetTemplate(
 		client.API.Indices.GetTemplate.WithName(id),
 		client.API.Indices.GetTemplate.WithContext(context.Background()),
@@ -95,18 +165,52 @@ func resourceElasticsearchIndexTemplateRead(d *schema.ResourceData, meta interfa
 	}
 	body := string(b)
 
+	templates := make(map[string]interface{})
+	if err := json.Unmarshal(b, &templates); err != nil {
+		return err
+	}
+
 	log.Debugf("Get index template %s successfully:\n%s", id, body)
 	d.Set("name", d.Id())
 	d.Set("template", body)
+	d.Set("managed_by", getManagedBy(templates[id], indexTemplateManagedByPath))
 	return nil
 }
 
+// resourceElasticsearchIndexTemplateCustomizeDiff compute the composable templates that overlap the legacy template
+func resourceElasticsearchIndexTemplateCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() != "" && !d.HasChange("template") {
+		return nil
+	}
+	if !d.NewValueKnown("template") {
+		return d.SetNewComputed("overlapping_composable_templates")
+	}
+
+	template := &IndexTemplateLegacySpec{}
+	if err := json.Unmarshal([]byte(d.Get("template").(string)), template); err != nil {
+		return err
+	}
+
+	// Composable templates are not supported before Elasticsearch 7.8
+	composableTemplates, err := getComposableIndexTemplates("", meta.(*providerConf).client)
+	if err != nil {
+		log.Warnf("Composable index templates can't be read to check overlapping templates: %s", err)
+		return d.SetNew("overlapping_composable_templates", []string{})
+	}
+	composablePatterns := make(map[string][]string, len(composableTemplates))
+	for _, composableTemplate := range composableTemplates {
+		composablePatterns[composableTemplate.Name] = composableTemplate.IndexTemplate.IndexPatterns
+	}
+
+	return d.SetNew("overlapping_composable_templates", overlappingIndexTemplates([]string(template.IndexPatterns), composablePatterns))
+}
+
 // resourceElasticsearchIndexTemplateDelete delete index template
 func resourceElasticsearchIndexTemplateDelete(d *schema.ResourceData, meta interface{}) error {
 
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Indices.DeleteTemplate(
 		id,
 		client.API.Indices.DeleteTemplate.WithContext(context.Background()),
@@ -137,12 +241,27 @@ func resourceElasticsearchIndexTemplateDelete(d *schema.ResourceData, meta inter
 // createIndexTemplate create or update index template
 func createIndexTemplate(d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
-	template := d.Get("template").(string)
+	client := meta.(*providerConf).client
+
+	templates, err := getIndexTemplatesLegacy(name, client)
+	if err != nil {
+		return err
+	}
+	if current, ok := templates[name]; ok && current != nil {
+		if err := checkManagedBy("Index template", name, getManagedBy(current.Mappings, []string{"_meta"}), d); err != nil {
+			return err
+		}
+	}
+
+	template, err := stampManagedBy(d.Get("template").(string), indexTemplateManagedByPath, d.Get("managed_by").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
 
-	client := meta.(*elastic.Client)
 	res, err := client.API.Indices.PutTemplate(
 		name,
 		strings.NewReader(template),
+		client.API.Indices.PutTemplate.WithCreate(isCreateOnly(d, meta)),
 		client.API.Indices.PutTemplate.WithContext(context.Background()),
 		client.API.Indices.PutTemplate.WithPretty(),
 	)
@@ -154,8 +273,94 @@ func createIndexTemplate(d *schema.ResourceData, meta interface{}) error {
 	defer res.Body.Close()
 
 	if res.IsError() {
+		if isCreateOnly(d, meta) && isAlreadyExistResponse(res) {
+			return alreadyExistError("Index template", name)
+		}
 		return errors.Errorf("Error when add index template %s: %s", name, res.String())
 	}
 
 	return nil
 }
+
+// getIndexTemplatesLegacy return the legacy templates matching name, or all templates if name is empty
+func getIndexTemplatesLegacy(name string, client *elastic.Client) (IndexTemplateLegacy, error) {
+	options := []func(*esapi.IndicesGetTemplateRequest){
+		client.API.Indices.GetTemplate.WithContext(context.Background()),
+		client.API.Indices.GetTemplate.WithPretty(),
+	}
+	if name != "" {
+		options = append(options, client.API.Indices.GetTemplate.WithName(name))
+	}
+
+	res, err := client.API.Indices.GetTemplate(options...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return nil, nil
+		}
+		return nil, errors.Errorf("Error when get index template %s: %s", name, res.String())
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	templates := make(IndexTemplateLegacy)
+	if err := json.Unmarshal(b, &templates); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// convertIndexTemplateLegacyToComposable convert legacy template to composable template
+// The order become the priority and settings, mappings and aliases move in template
+func convertIndexTemplateLegacyToComposable(legacy *IndexTemplateLegacySpec) *ComposableIndexTemplateSpec {
+	template := &ComposableIndexTemplateSpec{
+		IndexPatterns: []string(legacy.IndexPatterns),
+		Version:       legacy.Version,
+	}
+
+	// Composable template priority can't be negative
+	if legacy.Order > 0 {
+		template.Priority = legacy.Order
+	}
+
+	if !isEmptyJSONObject(legacy.Settings) || !isEmptyJSONObject(legacy.Mappings) || !isEmptyJSONObject(legacy.Aliases) {
+		template.Template = &ComposableIndexTemplateTemplate{}
+		if !isEmptyJSONObject(legacy.Settings) {
+			template.Template.Settings = legacy.Settings
+		}
+		if !isEmptyJSONObject(legacy.Mappings) {
+			template.Template.Mappings = legacy.Mappings
+		}
+		if !isEmptyJSONObject(legacy.Aliases) {
+			template.Template.Aliases = legacy.Aliases
+		}
+	}
+
+	return template
+}
+
+// overlappingIndexTemplates return the sorted names of templates with index patterns overlapping patterns
+// Elasticsearch only apply the composable template on indices matching both legacy and composable templates
+func overlappingIndexTemplates(patterns []string, templates map[string][]string) []string {
+	names := make([]string, 0)
+	for name, templatePatterns := range templates {
+	overlap:
+		for _, pattern := range patterns {
+			for _, templatePattern := range templatePatterns {
+				if indexPatternsOverlap(pattern, templatePattern) {
+					names = append(names, name)
+					break overlap
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -2,11 +2,12 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
-
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/pkg/errors"
@@ -34,14 +35,117 @@ func TestAccElasticsearchIndex(t *testing.T) {
 				),
 			},
 			{
-				ResourceName:      "elasticsearch_index_template.test",
-				ImportState:       true,
-				ImportStateVerify: true,
+				ResourceName:            "elasticsearch_index_template.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"overlapping_composable_templates"},
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndexTemplateCreateOnly(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIndexTemplate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexTemplateExists("elasticsearch_index_template.test"),
+				),
+			},
+			{
+				Config:      testElasticsearchIndexTemplateCreateOnly,
+				ExpectError: regexp.MustCompile("Index template terraform-test already exist"),
 			},
 		},
 	})
 }
 
+func TestConvertIndexTemplateLegacyToComposable(t *testing.T) {
+	legacy := &IndexTemplateLegacySpec{}
+	if err := json.Unmarshal([]byte(`{
+		"index_patterns": ["test*"],
+		"order": 2,
+		"version": 3,
+		"settings": {"index": {"refresh_interval": "5s"}},
+		"mappings": {},
+		"aliases": {"test-alias": {}}
+	}`), legacy); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &ComposableIndexTemplateSpec{
+		IndexPatterns: []string{"test*"},
+		Priority:      2,
+		Version:       3,
+		Template: &ComposableIndexTemplateTemplate{
+			Settings: map[string]interface{}{
+				"index": map[string]interface{}{
+					"refresh_interval": "5s",
+				},
+			},
+			Aliases: map[string]interface{}{
+				"test-alias": map[string]interface{}{},
+			},
+		},
+	}
+
+	if template := convertIndexTemplateLegacyToComposable(legacy); !reflect.DeepEqual(expected, template) {
+		t.Errorf("Expected %+v, got %+v", expected, template)
+	}
+}
+
+func TestIndexTemplateLegacySpecIndexPatterns(t *testing.T) {
+	cases := map[string]IndexPatterns{
+		`{"index_patterns": "logs-*"}`:            {"logs-*"},
+		`{"index_patterns": ["logs-*", "app-*"]}`: {"logs-*", "app-*"},
+		`{"order": 1}`:                            nil,
+	}
+
+	for body, expected := range cases {
+		template := &IndexTemplateLegacySpec{}
+		if err := json.Unmarshal([]byte(body), template); err != nil {
+			t.Fatalf("Error when unmarshal %s: %s", body, err)
+		}
+		if !reflect.DeepEqual(expected, template.IndexPatterns) {
+			t.Errorf("Expected %v for %s, got %v", expected, body, template.IndexPatterns)
+		}
+	}
+
+	if err := json.Unmarshal([]byte(`{"index_patterns": 1}`), &IndexTemplateLegacySpec{}); err == nil {
+		t.Error("Expected error for invalid index patterns")
+	}
+}
+
+func TestOverlappingIndexTemplates(t *testing.T) {
+	templates := map[string][]string{
+		"logs":    {"logs-*"},
+		"app":     {"metrics-app", "logs-app-*"},
+		"metrics": {"metrics-*"},
+	}
+
+	cases := []struct {
+		patterns []string
+		expected []string
+	}{
+		{[]string{"logs-app-2021"}, []string{"app", "logs"}},
+		{[]string{"metrics-web*"}, []string{"metrics"}},
+		{[]string{"traces-*"}, []string{}},
+	}
+
+	for _, tc := range cases {
+		if actual := overlappingIndexTemplates(tc.patterns, templates); !reflect.DeepEqual(tc.expected, actual) {
+			t.Errorf("Expected %v for %v, got %v", tc.expected, tc.patterns, actual)
+		}
+	}
+}
+
 func testCheckElasticsearchIndexTemplateExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -54,7 +158,7 @@ func testCheckElasticsearchIndexTemplateExists(name string) resource.TestCheckFu
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Indices.GetTemplate(
 			client.API.Indices.GetTemplate.WithName(rs.Primary.ID),
 			client.API.Indices.GetTemplate.WithContext(context.Background()),
@@ -80,7 +184,7 @@ func testCheckElasticsearchIndexTemplateDestroy(s *terraform.State) error {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Indices.DeleteTemplate(
 			rs.Primary.ID,
 			client.API.Indices.DeleteTemplate.WithContext(context.Background()),
@@ -139,3 +243,22 @@ resource "elasticsearch_index_template" "test" {
 EOF
 }
 `
+
+var testElasticsearchIndexTemplateCreateOnly = testElasticsearchIndexTemplate + `
+provider "elasticsearch" {
+  create_only = true
+}
+
+resource "elasticsearch_index_template" "other" {
+  name 		= "terraform-test"
+  template 	= <<EOF
+{
+  "index_patterns": [
+    "other"
+  ]
+}
+EOF
+
+  depends_on = [elasticsearch_index_template.test]
+}
+`
@@ -1,23 +1,74 @@
+// Manage ingest pipeline in Elasticsearch
+// API documentation:
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/put-pipeline-api.html
+//  - https://www.elastic.co/guide/en/elasticsearch/reference/current/simulate-pipeline-api.html
+// Supported version:
+//  - v7
+
 package es
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
 	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"io/ioutil"
-	"strings"
 )
 
+// ingestPipelineJSONRules are the rules used to canonicalize pipeline body
+var ingestPipelineJSONRules = &canonical.Rules{
+	Defaults: map[string]interface{}{
+		"_meta": map[string]interface{}{},
+	},
+	ServerFields: []string{"_meta." + managedByKey},
+}
+
+// ingestPipelineManagedByPath is the path of object that store the ownership marker
+var ingestPipelineManagedByPath = []string{"_meta"}
+
+// ingestProcessorTypes is the list of processors supported as typed blocks
+var ingestProcessorTypes = []string{"set", "rename", "remove", "grok", "date", "json", "script", "pipeline", "geoip", "user_agent"}
+
+// IngestPipelineSimulate is the simulate API request
+type IngestPipelineSimulate struct {
+	Pipeline interface{}                       `json:"pipeline"`
+	Docs     []*IngestPipelineSimulateDocument `json:"docs"`
+}
+
+// IngestPipelineSimulateDocument is a document used by simulate API
+type IngestPipelineSimulateDocument struct {
+	Source interface{} `json:"_source"`
+}
+
+// IngestPipelineSimulateResult is the simulate API response
+type IngestPipelineSimulateResult struct {
+	Docs []*IngestPipelineSimulateResultDoc `json:"docs"`
+}
+
+// IngestPipelineSimulateResultDoc is the result of one document on simulate API
+type IngestPipelineSimulateResultDoc struct {
+	Doc   *IngestPipelineSimulateDocument `json:"doc,omitempty"`
+	Error map[string]interface{}          `json:"error,omitempty"`
+}
+
 func resourceElasticsearchIngestPipeline() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceElasticsearchIngestPipelineCreate,
 		Read:   resourceElasticsearchIngestPipelineRead,
 		Update: resourceElasticsearchIngestPipelineUpdate,
 		Delete: resourceElasticsearchIngestPipelineDelete,
+
+		CustomizeDiff: resourceElasticsearchIngestPipelineCustomizeDiff,
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -26,13 +77,65 @@ func resourceElasticsearchIngestPipeline() *schema.Resource {
 			},
 			"body": {
 				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ExactlyOneOf:     []string{"body", "processor"},
 				DiffSuppressFunc: diffSuppressIngestPipeline,
-				Required:         true,
+				StateFunc:        canonicalJSONStateFunc(ingestPipelineJSONRules),
 				ValidateFunc:     validation.StringIsJSON,
 			},
+			"description": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"body"},
+			},
+			"version": {
+				Type:          schema.TypeInt,
+				Optional:      true,
+				ConflictsWith: []string{"body"},
+			},
+			"processor": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				ExactlyOneOf: []string{"body", "processor"},
+				Elem:         ingestProcessorResource(),
+			},
+			"on_failure": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"body"},
+				Elem:          ingestProcessorResource(),
+			},
+			"test_documents": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Documents source used to simulate the pipeline before put it",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsJSON,
+				},
+			},
+			"expected_output": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				RequiredWith: []string{"test_documents"},
+				Description:  "Documents source expected after the simulation, in the same order as test_documents",
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsJSON,
+				},
+			},
+			"managed_by": managedBySchema(),
+			"takeover":   takeoverSchema(),
 		},
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: importStateManagedBy("Ingest pipeline", func(id string, meta interface{}) (map[string]interface{}, error) {
+				pipeline, err := getIngestPipeline(id, meta.(*providerConf).client)
+				if err != nil {
+					return nil, err
+				}
+				return getManagedBy(pipeline, ingestPipelineManagedByPath), nil
+			}),
 		},
 	}
 }
@@ -44,52 +147,48 @@ func resourceElasticsearchIngestPipelineCreate(d *schema.ResourceData, meta inte
 		return err
 	}
 	d.SetId(d.Get("name").(string))
-	return nil
+	return resourceElasticsearchIngestPipelineRead(d, meta)
 }
 
 func resourceElasticsearchIngestPipelineRead(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
-	client := meta.(*elastic.Client)
-	res, err := client.Ingest.GetPipeline(
-		client.Ingest.GetPipeline.WithPipelineID(id),
-		client.Ingest.GetPipeline.WithContext(context.Background()),
-		client.Ingest.GetPipeline.WithPretty(),
-	)
+
+	pipeline, err := getIngestPipeline(id, meta.(*providerConf).client)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
-	if res.IsError() {
-		if res.StatusCode == 404 {
-			fmt.Printf("[WARN] Ingest Pipeline %s not found - removing from state", id)
-			log.Warnf("Ingest Pipeline %s not found - removing from state", id)
-			d.SetId("")
-			return nil
-		}
-		return errors.Errorf("Error when getting Ingest pipeline %s: %s", id, res.String())
-
+	if pipeline == nil {
+		fmt.Printf("[WARN] Ingest Pipeline %s not found - removing from state", id)
+		log.Warnf("Ingest Pipeline %s not found - removing from state", id)
+		d.SetId("")
+		return nil
 	}
-	b, err := ioutil.ReadAll(res.Body)
+
+	body, err := json.Marshal(pipeline)
 	if err != nil {
 		return err
 	}
-	body := string(b)
 
-	log.Debugf("Got ingest pipeline %s successfully:\n%s", id, body)
+	log.Debugf("Got ingest pipeline %s successfully:\n%s", id, string(body))
 	d.Set("name", d.Id())
-	d.Set("body", body)
+	d.Set("body", canonicalJSONStateFunc(ingestPipelineJSONRules)(string(body)))
+	d.Set("managed_by", getManagedBy(pipeline, ingestPipelineManagedByPath))
 	return nil
 
 }
 
 func resourceElasticsearchIngestPipelineUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutIngestPipeline(d, meta)
+	err := resourceElasticsearchPutIngestPipeline(d, meta)
+	if err != nil {
+		return err
+	}
+	return resourceElasticsearchIngestPipelineRead(d, meta)
 }
 
 func resourceElasticsearchIngestPipelineDelete(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 
 	res, err := client.Ingest.DeletePipeline(
 		id,
@@ -118,11 +217,396 @@ func resourceElasticsearchIngestPipelineDelete(d *schema.ResourceData, meta inte
 	return nil
 }
 
+// ingestProcessorResource is the processor block schema shared by processor and on_failure
+func ingestProcessorResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"if": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"tag": {
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ignore_failure": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"set": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"value": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"copy_from": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"override": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"ignore_empty_value": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"rename": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"target_field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"ignore_missing": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"remove": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ignore_missing": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"grok": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"patterns": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"pattern_definitions": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"trace_match": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"ignore_missing": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"date": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"formats": {
+							Type:     schema.TypeList,
+							Required: true,
+							MinItems: 1,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"target_field": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"timezone": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"locale": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"output_format": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+					},
+				},
+			},
+			"json": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"target_field": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"add_to_root": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"script": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"lang": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"source": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"id": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"params": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+						},
+					},
+				},
+			},
+			"pipeline": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+					},
+				},
+			},
+			"geoip": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"target_field": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"database_file": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"properties": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ignore_missing": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+			"user_agent": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"field": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"target_field": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"regex_file": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"properties": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ignore_missing": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// resourceElasticsearchIngestPipelineCustomizeDiff check typed processors, set body from them when they change
+// or when the pipeline was changed outside of Terraform, and simulate the pipeline on test documents
+// The simulation is skipped when the pipeline or documents are not yet known
+func resourceElasticsearchIngestPipelineCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+	for _, key := range []string{"processor", "on_failure"} {
+		for i, raw := range d.Get(key).([]interface{}) {
+			if err := checkIngestProcessor(raw); err != nil {
+				return errors.Wrapf(err, "%s.%d", key, i)
+			}
+		}
+	}
+
+	typedAttributes := []string{"description", "version", "processor", "on_failure"}
+	hasTypedChange := false
+	for _, key := range typedAttributes {
+		if d.HasChange(key) {
+			hasTypedChange = true
+		}
+	}
+
+	if d.Id() == "" || hasTypedChange || d.HasChange("body") || d.HasChange("test_documents") || d.HasChange("expected_output") {
+		known := true
+		for _, key := range append(typedAttributes, "body", "test_documents", "expected_output") {
+			if !d.NewValueKnown(key) {
+				known = false
+			}
+		}
+		documents := d.Get("test_documents").([]interface{})
+		if known && len(documents) > 0 {
+			body, err := buildIngestPipelineBody(d.Get)
+			if err != nil {
+				return err
+			}
+			if err := checkIngestPipelineSimulation(body, documents, d.Get("expected_output").([]interface{}), meta.(*providerConf).client); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(d.Get("processor").([]interface{})) == 0 || (d.Id() == "" && !hasTypedChange) {
+		return nil
+	}
+	for _, key := range typedAttributes {
+		if !d.NewValueKnown(key) {
+			return d.SetNewComputed("body")
+		}
+	}
+
+	// The body read from the API is compared with the body built from typed processors to detect changes made outside of Terraform
+	body, err := buildIngestPipelineBody(d.Get)
+	if err != nil {
+		return err
+	}
+	current, _ := d.GetChange("body")
+	if !hasTypedChange && canonical.Equal(current.(string), body, ingestPipelineJSONRules) {
+		return nil
+	}
+
+	return d.SetNew("body", canonicalJSONStateFunc(ingestPipelineJSONRules)(body))
+}
+
 func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
-	body := d.Get("body").(string)
+	body, err := buildIngestPipelineBody(d.Get)
+	if err != nil {
+		return err
+	}
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
+
+	if documents := d.Get("test_documents").([]interface{}); len(documents) > 0 {
+		if err := checkIngestPipelineSimulation(body, documents, d.Get("expected_output").([]interface{}), client); err != nil {
+			return err
+		}
+	}
+
+	current, err := getIngestPipeline(name, client)
+	if err != nil {
+		return err
+	}
+	if current != nil && isCreateOnly(d, meta) {
+		return alreadyExistError("Ingest pipeline", name)
+	}
+	if err := checkManagedBy("Ingest pipeline", name, getManagedBy(current, ingestPipelineManagedByPath), d); err != nil {
+		return err
+	}
+	body, err = stampManagedBy(body, ingestPipelineManagedByPath, d.Get("managed_by").(map[string]interface{}))
+	if err != nil {
+		return err
+	}
 
 	res, err := client.Ingest.PutPipeline(
 		name,
@@ -142,4 +626,247 @@ func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interfa
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// getIngestPipeline return the pipeline body without the ID wrapper returned by the API
+// It return nil if pipeline not exist
+func getIngestPipeline(id string, client *elastic.Client) (map[string]interface{}, error) {
+	res, err := client.Ingest.GetPipeline(
+		client.Ingest.GetPipeline.WithPipelineID(id),
+		client.Ingest.GetPipeline.WithContext(context.Background()),
+		client.Ingest.GetPipeline.WithPretty(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		if res.StatusCode == 404 {
+			return nil, nil
+		}
+		return nil, errors.Errorf("Error when getting Ingest pipeline %s: %s", id, res.String())
+	}
+
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	pipelines := make(map[string]map[string]interface{})
+	if err := json.Unmarshal(b, &pipelines); err != nil {
+		return nil, err
+	}
+
+	return pipelines[id], nil
+}
+
+// simulateIngestPipeline run the pipeline on documents without storing it
+func simulateIngestPipeline(pipeline string, documents []interface{}, client *elastic.Client) (*IngestPipelineSimulateResult, error) {
+	simulate := &IngestPipelineSimulate{
+		Docs: make([]*IngestPipelineSimulateDocument, 0, len(documents)),
+	}
+	if err := json.Unmarshal([]byte(pipeline), &simulate.Pipeline); err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		doc := &IngestPipelineSimulateDocument{}
+		if err := json.Unmarshal([]byte(document.(string)), &doc.Source); err != nil {
+			return nil, err
+		}
+		simulate.Docs = append(simulate.Docs, doc)
+	}
+
+	b, err := json.Marshal(simulate)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Simulate ingest pipeline: %s", string(b))
+
+	res, err := client.Ingest.Simulate(
+		bytes.NewReader(b),
+		client.Ingest.Simulate.WithContext(context.Background()),
+		client.Ingest.Simulate.WithPretty(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return nil, errors.Errorf("Error when simulate ingest pipeline: %s", res.String())
+	}
+
+	b, err = ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	result := &IngestPipelineSimulateResult{}
+	if err := json.Unmarshal(b, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkIngestPipelineSimulation simulate the pipeline and check there are no processor error
+// and that the documents match the expected output if provided
+func checkIngestPipelineSimulation(pipeline string, documents []interface{}, expectedOutput []interface{}, client *elastic.Client) error {
+	if len(expectedOutput) > 0 && len(expectedOutput) != len(documents) {
+		return errors.Errorf("expected_output must have the same number of documents as test_documents, got %d instead of %d", len(expectedOutput), len(documents))
+	}
+
+	result, err := simulateIngestPipeline(pipeline, documents, client)
+	if err != nil {
+		return err
+	}
+	if len(result.Docs) != len(documents) {
+		return errors.Errorf("Simulate ingest pipeline return %d documents instead of %d", len(result.Docs), len(documents))
+	}
+
+	for i, doc := range result.Docs {
+		if doc.Error != nil {
+			return errors.Errorf("Simulate ingest pipeline failed on test_documents.%d: %s: %s", i, doc.Error["type"], doc.Error["reason"])
+		}
+		if len(expectedOutput) == 0 {
+			continue
+		}
+
+		var expected interface{}
+		if err := json.Unmarshal([]byte(expectedOutput[i].(string)), &expected); err != nil {
+			return err
+		}
+		var source interface{}
+		if doc.Doc != nil {
+			source = doc.Doc.Source
+		}
+		if !reflect.DeepEqual(expected, source) {
+			return errors.Errorf("Simulate ingest pipeline on test_documents.%d return %s instead of expected_output.%d %s", i, convertInterfaceToJSONString(source), i, convertInterfaceToJSONString(expected))
+		}
+	}
+
+	return nil
+}
+
+// buildIngestPipelineBody return the pipeline body as JSON string
+// Typed processors take precedence over the computed body attribute
+func buildIngestPipelineBody(get func(string) interface{}) (string, error) {
+	processors := get("processor").([]interface{})
+	if len(processors) == 0 {
+		return get("body").(string), nil
+	}
+
+	pipeline := map[string]interface{}{
+		"processors": buildIngestProcessors(processors),
+	}
+	if description := get("description").(string); description != "" {
+		pipeline["description"] = description
+	}
+	if version := get("version").(int); version != 0 {
+		pipeline["version"] = version
+	}
+	if onFailure := get("on_failure").([]interface{}); len(onFailure) > 0 {
+		pipeline["on_failure"] = buildIngestProcessors(onFailure)
+	}
+
+	b, err := json.Marshal(pipeline)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// checkIngestProcessor check the processor block has exactly one processor type
+func checkIngestProcessor(raw interface{}) error {
+	if raw == nil {
+		return errors.Errorf("Processor must have one of %s", strings.Join(ingestProcessorTypes, ", "))
+	}
+	m := raw.(map[string]interface{})
+	types := blockTypes(m, ingestProcessorTypes...)
+	if len(types) != 1 {
+		return errors.Errorf("Processor must have exactly one of %s, got %d", strings.Join(ingestProcessorTypes, ", "), len(types))
+	}
+
+	switch types[0] {
+	case "set":
+		options := m["set"].([]interface{})[0].(map[string]interface{})
+		if (options["value"].(string) == "") == (options["copy_from"].(string) == "") {
+			return errors.New("Set processor must have exactly one of value or copy_from")
+		}
+	case "script":
+		options, _ := m["script"].([]interface{})[0].(map[string]interface{})
+		if options == nil || (options["source"].(string) == "") == (options["id"].(string) == "") {
+			return errors.New("Script processor must have exactly one of source or id")
+		}
+	}
+
+	return nil
+}
+
+// buildIngestProcessors convert processor blocks to processor objects
+func buildIngestProcessors(raws []interface{}) []interface{} {
+	processors := make([]interface{}, 0, len(raws))
+
+	for _, raw := range raws {
+		m := raw.(map[string]interface{})
+		processorType := blockTypes(m, ingestProcessorTypes...)[0]
+
+		var options map[string]interface{}
+		if l := m[processorType].([]interface{}); l[0] != nil {
+			options = buildIngestProcessorOptions(l[0].(map[string]interface{}))
+		} else {
+			options = make(map[string]interface{})
+		}
+		if condition := m["if"].(string); condition != "" {
+			options["if"] = condition
+		}
+		if tag := m["tag"].(string); tag != "" {
+			options["tag"] = tag
+		}
+		if m["ignore_failure"].(bool) {
+			options["ignore_failure"] = true
+		}
+
+		processors = append(processors, map[string]interface{}{
+			processorType: options,
+		})
+	}
+
+	return processors
+}
+
+// buildIngestProcessorOptions convert processor options block to processor options object
+// Empty values are not sent, booleans are only sent when they differ from the Elasticsearch default
+func buildIngestProcessorOptions(m map[string]interface{}) map[string]interface{} {
+	boolDefaults := map[string]bool{
+		"override": true,
+	}
+	options := make(map[string]interface{})
+
+	for key, raw := range m {
+		switch v := raw.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			if key == "params" {
+				options[key] = json.RawMessage(v)
+			} else {
+				options[key] = v
+			}
+		case bool:
+			if v != boolDefaults[key] {
+				options[key] = v
+			}
+		case []interface{}:
+			if len(v) > 0 {
+				options[key] = v
+			}
+		case map[string]interface{}:
+			if len(v) > 0 {
+				options[key] = v
+			}
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,477 @@
+package es
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+	"github.com/pkg/errors"
+)
+
+func TestAccElasticsearchIngestPipeline(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIngestPipelineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIngestPipeline,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineExists("elasticsearch_ingest_pipeline.test"),
+				),
+			},
+			{
+				Config: testElasticsearchIngestPipelineUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineExists("elasticsearch_ingest_pipeline.test"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_ingest_pipeline.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIngestPipelineSimulate(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIngestPipelineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIngestPipelineSimulate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineExists("elasticsearch_ingest_pipeline.test"),
+				),
+			},
+			{
+				Config:      testElasticsearchIngestPipelineSimulateFailed,
+				ExpectError: regexp.MustCompile("instead of expected_output.0"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIngestPipelineTyped(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIngestPipelineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIngestPipelineTyped,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineExists("elasticsearch_ingest_pipeline.test"),
+					resource.TestCheckResourceAttrSet("elasticsearch_ingest_pipeline.test", "body"),
+				),
+			},
+			{
+				Config:   testElasticsearchIngestPipelineTyped,
+				PlanOnly: true,
+			},
+			{
+				PreConfig:          testPutElasticsearchIngestPipeline(t, "terraform-test", `{"description": "changed outside of terraform", "processors": []}`),
+				Config:             testElasticsearchIngestPipelineTyped,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testElasticsearchIngestPipelineTyped,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_ingest_pipeline.test", "description", "describe pipeline"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIngestPipelineManagedBy(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIngestPipelineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchIngestPipelineManagedBy,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineExists("elasticsearch_ingest_pipeline.test"),
+					resource.TestCheckResourceAttr("elasticsearch_ingest_pipeline.test", "managed_by.workspace", "team-a"),
+				),
+			},
+			{
+				Config:      testElasticsearchIngestPipelineManagedByConflict,
+				ExpectError: regexp.MustCompile("set takeover to true to overwrite it"),
+			},
+			{
+				ResourceName:     "elasticsearch_ingest_pipeline.test",
+				ImportState:      true,
+				ImportStateId:    "terraform-test",
+				ImportStateCheck: testCheckElasticsearchImportedManagedBy("team-a"),
+			},
+			{
+				Config:      testElasticsearchIngestPipelineCreateOnly,
+				ExpectError: regexp.MustCompile("already exist"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIngestPipelineCreateOnlyTakeover(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIngestPipelineDestroy,
+		Steps: []resource.TestStep{
+			{
+				PreConfig:   testPutElasticsearchIngestPipeline(t, "terraform-test", `{"description": "owned by team-b", "processors": [], "_meta": {"managed_by": {"workspace": "team-b"}}}`),
+				Config:      testElasticsearchIngestPipelineCreateOnlyExisting,
+				ExpectError: regexp.MustCompile("already exist"),
+			},
+			{
+				Config: testElasticsearchIngestPipelineCreateOnlyTakeover,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineExists("elasticsearch_ingest_pipeline.test"),
+					resource.TestCheckResourceAttr("elasticsearch_ingest_pipeline.test", "managed_by.workspace", "team-a"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchIngestPipelineExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No ingest pipeline ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		pipeline, err := getIngestPipeline(rs.Primary.ID, meta.(*providerConf).client)
+		if err != nil {
+			return err
+		}
+		if pipeline == nil {
+			return errors.Errorf("Ingest pipeline %s not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchImportedManagedBy(workspace string) resource.ImportStateCheckFunc {
+	return func(states []*terraform.InstanceState) error {
+		if len(states) != 1 {
+			return errors.Errorf("Expected 1 imported object, got %d", len(states))
+		}
+		if actual := states[0].Attributes["managed_by.workspace"]; actual != workspace {
+			return errors.Errorf("Expected imported managed_by.workspace to be %s, got %s", workspace, actual)
+		}
+
+		return nil
+	}
+}
+
+func testPutElasticsearchIngestPipeline(t *testing.T, name string, body string) func() {
+	return func() {
+		client := testAccProvider.Meta().(*providerConf).client
+		res, err := client.Ingest.PutPipeline(name, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			t.Fatalf("Error when put ingest pipeline %s: %s", name, res.String())
+		}
+	}
+}
+
+func testCheckElasticsearchIngestPipelineDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_ingest_pipeline" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		pipeline, err := getIngestPipeline(rs.Primary.ID, meta.(*providerConf).client)
+		if err != nil {
+			return err
+		}
+		if pipeline == nil {
+			return nil
+		}
+
+		return fmt.Errorf("Ingest pipeline %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testElasticsearchIngestPipeline = `
+resource "elasticsearch_ingest_pipeline" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "description" : "describe pipeline",
+  "version": 123,
+  "processors" : [
+    {
+      "set" : {
+        "field": "foo",
+        "value": "bar"
+      }
+    }
+  ]
+}
+EOF
+}
+`
+
+var testElasticsearchIngestPipelineUpdate = `
+resource "elasticsearch_ingest_pipeline" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "description" : "describe pipeline",
+  "version": 124,
+  "processors" : [
+    {
+      "set" : {
+        "field": "foo",
+        "value": "bar"
+      }
+    },
+    {
+      "set" : {
+        "field": "abc",
+        "value": "xyz"
+      }
+    }
+  ]
+}
+EOF
+}
+`
+
+var testElasticsearchIngestPipelineSimulate = `
+resource "elasticsearch_ingest_pipeline" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "description" : "describe pipeline",
+  "processors" : [
+    {
+      "set" : {
+        "field": "foo",
+        "value": "bar"
+      }
+    }
+  ]
+}
+EOF
+
+  test_documents = [
+    jsonencode({ message = "test" })
+  ]
+
+  expected_output = [
+    jsonencode({ message = "test", foo = "bar" })
+  ]
+}
+`
+
+var testElasticsearchIngestPipelineSimulateFailed = `
+resource "elasticsearch_ingest_pipeline" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "description" : "describe pipeline",
+  "processors" : [
+    {
+      "set" : {
+        "field": "foo",
+        "value": "baz"
+      }
+    }
+  ]
+}
+EOF
+
+  test_documents = [
+    jsonencode({ message = "test" })
+  ]
+
+  expected_output = [
+    jsonencode({ message = "test", foo = "bar" })
+  ]
+}
+`
+
+var testElasticsearchIngestPipelineTyped = `
+resource "elasticsearch_ingest_pipeline" "test" {
+  name        = "terraform-test"
+  description = "describe pipeline"
+  version     = 1
+
+  processor {
+    tag = "parse"
+    grok {
+      field    = "message"
+      patterns = ["%%{IP:client} %%{WORD:method} %%{URIPATHPARAM:request}"]
+    }
+  }
+
+  processor {
+    if = "ctx.method == 'GET'"
+    set {
+      field = "read_only"
+      value = "true"
+    }
+  }
+
+  processor {
+    ignore_failure = true
+    remove {
+      field = ["message"]
+    }
+  }
+
+  on_failure {
+    set {
+      field = "error.message"
+      value = "{{ _ingest.on_failure_message }}"
+    }
+  }
+
+  test_documents = [
+    jsonencode({ message = "127.0.0.1 GET /index.html" })
+  ]
+
+  expected_output = [
+    jsonencode({ client = "127.0.0.1", method = "GET", request = "/index.html", read_only = "true" })
+  ]
+}
+`
+
+var testElasticsearchIngestPipelineManagedBy = `
+resource "elasticsearch_ingest_pipeline" "test" {
+  name = "terraform-test"
+  body = jsonencode({
+    description = "owned pipeline"
+    processors = [
+      {
+        set = {
+          field = "foo"
+          value = "bar"
+        }
+      }
+    ]
+  })
+
+  managed_by = {
+    workspace = "team-a"
+  }
+}
+`
+
+var testElasticsearchIngestPipelineManagedByConflict = testElasticsearchIngestPipelineManagedBy + `
+provider "elasticsearch" {
+  create_only = false
+}
+
+resource "elasticsearch_ingest_pipeline" "other" {
+  name = "terraform-test"
+  body = jsonencode({
+    description = "other pipeline"
+    processors  = []
+  })
+
+  managed_by = {
+    workspace = "team-b"
+  }
+
+  depends_on = [elasticsearch_ingest_pipeline.test]
+}
+`
+
+var testElasticsearchIngestPipelineCreateOnly = testElasticsearchIngestPipelineManagedBy + `
+provider "elasticsearch" {
+  create_only = true
+}
+
+resource "elasticsearch_ingest_pipeline" "other" {
+  name = "terraform-test"
+  body = jsonencode({
+    description = "other pipeline"
+    processors  = []
+  })
+
+  managed_by = {
+    workspace = "team-a"
+  }
+
+  depends_on = [elasticsearch_ingest_pipeline.test]
+}
+`
+
+var testElasticsearchIngestPipelineCreateOnlyExisting = `
+provider "elasticsearch" {
+  create_only = true
+}
+
+resource "elasticsearch_ingest_pipeline" "test" {
+  name = "terraform-test"
+  body = jsonencode({
+    description = "owned by team-a"
+    processors  = []
+  })
+
+  managed_by = {
+    workspace = "team-a"
+  }
+}
+`
+
+var testElasticsearchIngestPipelineCreateOnlyTakeover = `
+provider "elasticsearch" {
+  create_only = true
+}
+
+resource "elasticsearch_ingest_pipeline" "test" {
+  name     = "terraform-test"
+  takeover = true
+  body = jsonencode({
+    description = "owned by team-a"
+    processors  = []
+  })
+
+  managed_by = {
+    workspace = "team-a"
+  }
+}
+`
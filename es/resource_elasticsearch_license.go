@@ -13,7 +13,6 @@ import (
 	"io/ioutil"
 	"strings"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/pkg/errors"
@@ -63,6 +62,7 @@ func resourceElasticsearchLicense() *schema.Resource {
 				Optional: true,
 				Computed: true,
 			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
@@ -79,9 +79,11 @@ func resourceElasticsearchLicenseCreate(d *schema.ResourceData, meta interface{}
 
 // resourceElasticsearchLicense update license
 func resourceElasticsearchLicenseUpdate(d *schema.ResourceData, meta interface{}) error {
-	err := createLicense(d, meta)
-	if err != nil {
-		return err
+	if !onlyDeletionProtectionChanged(d, resourceElasticsearchLicense().Schema) {
+		err := createLicense(d, meta)
+		if err != nil {
+			return err
+		}
 	}
 	return resourceElasticsearchLicenseRead(d, meta)
 }
@@ -89,7 +91,7 @@ func resourceElasticsearchLicenseUpdate(d *schema.ResourceData, meta interface{}
 // resourceElasticsearchLicenseRead read license
 func resourceElasticsearchLicenseRead(d *schema.ResourceData, meta interface{}) error {
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.License.Get(
 		client.API.License.Get.WithContext(context.Background()),
 		client.API.License.Get.WithPretty(),
@@ -139,7 +141,11 @@ func resourceElasticsearchLicenseRead(d *schema.ResourceData, meta interface{})
 // resourceElasticsearchLicenseDelete delete license
 func resourceElasticsearchLicenseDelete(d *schema.ResourceData, meta interface{}) error {
 
-	client := meta.(*elastic.Client)
+	if err := checkDeletionProtection("License", d.Id(), d); err != nil {
+		return err
+	}
+
+	client := meta.(*providerConf).client
 	res, err := client.API.License.Delete(
 		client.API.License.Delete.WithContext(context.Background()),
 		client.API.License.Delete.WithPretty(),
@@ -171,7 +177,7 @@ func createLicense(d *schema.ResourceData, meta interface{}) error {
 	license := d.Get("license").(string)
 	useBasicLicense := d.Get("use_basic_license").(bool)
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	var err error
 	var res *esapi.Response
 	// Use enterprise lisence
@@ -13,7 +13,7 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -88,6 +88,7 @@ func resourceElasticsearchSecurityRole() *schema.Resource {
 				Optional:         true,
 				Default:          "{}",
 				DiffSuppressFunc: suppressEquivalentJSON,
+				StateFunc:        canonicalJSONStateFunc(nil),
 			},
 			"indices": {
 				Type:     schema.TypeSet,
@@ -113,12 +114,14 @@ func resourceElasticsearchSecurityRole() *schema.Resource {
 							Optional:         true,
 							Default:          "{}",
 							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
 						},
 						"field_security": {
 							Type:             schema.TypeString,
 							Optional:         true,
 							Default:          "{}",
 							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
 						},
 					},
 				},
@@ -157,6 +160,16 @@ func resourceElasticsearchSecurityRole() *schema.Resource {
 func resourceElasticsearchSecurityRoleCreate(d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 
+	if err := checkCreateOnly("Role", name, meta, func() (*esapi.Response, error) {
+		client := meta.(*providerConf).client
+		return client.API.Security.GetRole(
+			client.API.Security.GetRole.WithContext(context.Background()),
+			client.API.Security.GetRole.WithName(name),
+		)
+	}); err != nil {
+		return err
+	}
+
 	err := createRole(d, meta)
 	if err != nil {
 		return err
@@ -175,7 +188,7 @@ func resourceElasticsearchSecurityRoleRead(d *schema.ResourceData, meta interfac
 
 	log.Debugf("Role id:  %s", id)
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.GetRole(
 		client.API.Security.GetRole.WithContext(context.Background()),
 		client.API.Security.GetRole.WithPretty(),
@@ -240,7 +253,7 @@ func resourceElasticsearchSecurityRoleDelete(d *schema.ResourceData, meta interf
 	id := d.Id()
 	log.Debugf("Role id: %s", id)
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.DeleteRole(
 		id,
 		client.API.Security.DeleteRole.WithContext(context.Background()),
@@ -303,7 +316,7 @@ func createRole(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.PutRole(
 		name,
 		bytes.NewReader(data),
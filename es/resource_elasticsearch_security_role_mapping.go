@@ -13,7 +13,7 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -57,6 +57,7 @@ func resourceElasticsearchSecurityRoleMapping() *schema.Resource {
 				Type:             schema.TypeString,
 				Required:         true,
 				DiffSuppressFunc: suppressEquivalentJSON,
+				StateFunc:        canonicalJSONStateFunc(nil),
 			},
 			"roles": {
 				Type: schema.TypeSet,
@@ -70,6 +71,7 @@ func resourceElasticsearchSecurityRoleMapping() *schema.Resource {
 				Optional:         true,
 				Default:          "{}",
 				DiffSuppressFunc: suppressEquivalentJSON,
+				StateFunc:        canonicalJSONStateFunc(nil),
 			},
 		},
 	}
@@ -79,6 +81,16 @@ func resourceElasticsearchSecurityRoleMapping() *schema.Resource {
 func resourceElasticsearchSecurityRoleMappingCreate(d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
 
+	if err := checkCreateOnly("Role mapping", name, meta, func() (*esapi.Response, error) {
+		client := meta.(*providerConf).client
+		return client.API.Security.GetRoleMapping(
+			client.API.Security.GetRoleMapping.WithContext(context.Background()),
+			client.API.Security.GetRoleMapping.WithName(name),
+		)
+	}); err != nil {
+		return err
+	}
+
 	err := createRoleMapping(d, meta)
 	if err != nil {
 		return err
@@ -96,7 +108,7 @@ func resourceElasticsearchSecurityRoleMappingRead(d *schema.ResourceData, meta i
 
 	log.Debugf("Role mapping id:  %s", id)
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.GetRoleMapping(
 		client.API.Security.GetRoleMapping.WithContext(context.Background()),
 		client.API.Security.GetRoleMapping.WithPretty(),
@@ -158,7 +170,7 @@ func resourceElasticsearchSecurityRoleMappingDelete(d *schema.ResourceData, meta
 	id := d.Id()
 	log.Debugf("Role mapping id: %s", id)
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.DeleteRoleMapping(
 		id,
 		client.API.Security.DeleteRoleMapping.WithContext(context.Background()),
@@ -211,7 +223,7 @@ func createRoleMapping(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.PutRoleMapping(
 		name,
 		bytes.NewReader(data),
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/pkg/errors"
@@ -54,7 +53,7 @@ func testCheckElasticsearchSecurityRoleMappingExists(name string) resource.TestC
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Security.GetRoleMapping(
 			client.API.Security.GetRoleMapping.WithContext(context.Background()),
 			client.API.Security.GetRoleMapping.WithPretty(),
@@ -80,7 +79,7 @@ func testCheckElasticsearchSecurityRoleMappingDestroy(s *terraform.State) error
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Security.GetRoleMapping(
 			client.API.Security.GetRoleMapping.WithContext(context.Background()),
 			client.API.Security.GetRoleMapping.WithPretty(),
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/pkg/errors"
@@ -54,7 +53,7 @@ func testCheckElasticsearchSecurityRoleExists(name string) resource.TestCheckFun
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Security.GetRole(
 			client.API.Security.GetRole.WithContext(context.Background()),
 			client.API.Security.GetRole.WithPretty(),
@@ -80,7 +79,7 @@ func testCheckElasticsearchSecurityRoleDestroy(s *terraform.State) error {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Security.GetRole(
 			client.API.Security.GetRole.WithContext(context.Background()),
 			client.API.Security.GetRole.WithPretty(),
@@ -13,7 +13,7 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -84,7 +84,9 @@ func resourceElasticsearchSecurityUser() *schema.Resource {
 				Optional:         true,
 				Default:          "{}",
 				DiffSuppressFunc: suppressEquivalentJSON,
+				StateFunc:        canonicalJSONStateFunc(nil),
 			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
@@ -93,6 +95,16 @@ func resourceElasticsearchSecurityUser() *schema.Resource {
 func resourceElasticsearchSecurityUserCreate(d *schema.ResourceData, meta interface{}) error {
 	username := d.Get("username").(string)
 
+	if err := checkCreateOnly("User", username, meta, func() (*esapi.Response, error) {
+		client := meta.(*providerConf).client
+		return client.API.Security.GetUser(
+			client.API.Security.GetUser.WithContext(context.Background()),
+			client.API.Security.GetUser.WithUsername(username),
+		)
+	}); err != nil {
+		return err
+	}
+
 	err := createUser(d, meta, false)
 	if err != nil {
 		return err
@@ -111,7 +123,7 @@ func resourceElasticsearchSecurityUserRead(d *schema.ResourceData, meta interfac
 
 	log.Debugf("User id:  %s", id)
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.GetUser(
 		client.API.Security.GetUser.WithContext(context.Background()),
 		client.API.Security.GetUser.WithPretty(),
@@ -177,7 +189,7 @@ func resourceElasticsearchSecurityUserUpdate(d *schema.ResourceData, meta interf
 			return err
 		}
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Security.ChangePassword(
 			bytes.NewReader(data),
 			client.API.Security.ChangePassword.WithUsername(id),
@@ -219,7 +231,11 @@ func resourceElasticsearchSecurityUserDelete(d *schema.ResourceData, meta interf
 	id := d.Id()
 	log.Debugf("User id: %s", id)
 
-	client := meta.(*elastic.Client)
+	if err := checkDeletionProtection("User", id, d); err != nil {
+		return err
+	}
+
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.DeleteUser(
 		id,
 		client.API.Security.DeleteUser.WithContext(context.Background()),
@@ -288,7 +304,7 @@ func createUser(d *schema.ResourceData, meta interface{}, isUpdate bool) error {
 		return err
 	}
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Security.PutUser(
 		username,
 		bytes.NewReader(data),
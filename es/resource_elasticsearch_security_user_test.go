@@ -3,9 +3,9 @@ package es
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"testing"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/pkg/errors"
@@ -42,6 +42,36 @@ func TestAccElasticsearchSecurityUser(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchSecurityUserDeletionProtection(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchSecurityUserDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchSecurityUserDeletionProtection,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchSecurityUserExists("elasticsearch_user.test"),
+				),
+			},
+			{
+				Config:      testElasticsearchSecurityUserDeletionProtection,
+				Destroy:     true,
+				ExpectError: regexp.MustCompile("is protected against deletion"),
+			},
+			{
+				Config: testElasticsearchSecurityUser,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchSecurityUserExists("elasticsearch_user.test"),
+				),
+			},
+		},
+	})
+}
+
 func testCheckElasticsearchSecurityUserExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -54,7 +84,7 @@ func testCheckElasticsearchSecurityUserExists(name string) resource.TestCheckFun
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Security.GetUser(
 			client.API.Security.GetUser.WithContext(context.Background()),
 			client.API.Security.GetUser.WithPretty(),
@@ -80,7 +110,7 @@ func testCheckElasticsearchSecurityUserDestroy(s *terraform.State) error {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Security.GetUser(
 			client.API.Security.GetUser.WithContext(context.Background()),
 			client.API.Security.GetUser.WithPretty(),
@@ -123,3 +153,16 @@ resource "elasticsearch_user" "test" {
   roles 	= ["kibana_user"]
 }
 `
+
+var testElasticsearchSecurityUserDeletionProtection = `
+resource "elasticsearch_user" "test" {
+  username 	= "terraform-test"
+  enabled 	= "true"
+  email 	= "no@no.no"
+  full_name = "test"
+  password 	= "changeme"
+  roles 	= ["kibana_user"]
+
+  deletion_protection = true
+}
+`
@@ -11,9 +11,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strings"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,11 +25,28 @@ type SnapshotLifecyclePolicy map[string]*SnapshotLifecyclePolicyGet
 
 // SnapshotLifecyclePolicySpec is the snapshot lifecycle policy object
 type SnapshotLifecyclePolicySpec struct {
-	Schedule   string      `json:"schedule"`
-	Name       string      `json:"name"`
-	Repository string      `json:"repository"`
-	Configs    interface{} `json:"config,omitempty"`
-	Retention  interface{} `json:"retention,omitempty"`
+	Schedule   string                            `json:"schedule"`
+	Name       string                            `json:"name"`
+	Repository string                            `json:"repository"`
+	Configs    *SnapshotLifecyclePolicyConfig    `json:"config,omitempty"`
+	Retention  *SnapshotLifecyclePolicyRetention `json:"retention,omitempty"`
+}
+
+// SnapshotLifecyclePolicyConfig is the snapshot configuration used by the policy
+type SnapshotLifecyclePolicyConfig struct {
+	Indices            interface{} `json:"indices,omitempty"`
+	IgnoreUnavailable  *bool       `json:"ignore_unavailable,omitempty"`
+	IncludeGlobalState *bool       `json:"include_global_state,omitempty"`
+	Partial            *bool       `json:"partial,omitempty"`
+	FeatureStates      []string    `json:"feature_states,omitempty"`
+	Metadata           interface{} `json:"metadata,omitempty"`
+}
+
+// SnapshotLifecyclePolicyRetention is the retention used by the policy
+type SnapshotLifecyclePolicyRetention struct {
+	ExpireAfter string `json:"expire_after,omitempty"`
+	MinCount    *int   `json:"min_count,omitempty"`
+	MaxCount    *int   `json:"max_count,omitempty"`
 }
 
 // SnapshotLifecyclePolicyGet is the policy
@@ -47,6 +66,112 @@ func resourceElasticsearchSnapshotLifecyclePolicy() *schema.Resource {
 			State: schema.ImportStatePassthrough,
 		},
 
+		SchemaVersion: 1,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceElasticsearchSnapshotLifecyclePolicyV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceElasticsearchSnapshotLifecyclePolicyStateUpgradeV0,
+			},
+		},
+
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"snapshot_name": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"schedule": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validateElasticsearchCron,
+			},
+			"repository": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"config": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"indices": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ignore_unavailable": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"include_global_state": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+						"partial": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"feature_states": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"metadata": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "{}",
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+							ValidateFunc:     validation.StringIsJSON,
+						},
+					},
+				},
+			},
+			"retention": {
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"expire_after": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateElasticsearchDuration,
+						},
+						"min_count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+						"max_count": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							ValidateFunc: validation.IntAtLeast(0),
+						},
+					},
+				},
+			},
+			"deletion_protection": deletionProtectionSchema(),
+		},
+	}
+}
+
+// resourceElasticsearchSnapshotLifecyclePolicyV0 is the schema before config and retention became blocks
+func resourceElasticsearchSnapshotLifecyclePolicyV0() *schema.Resource {
+	return &schema.Resource{
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -77,11 +202,47 @@ func resourceElasticsearchSnapshotLifecyclePolicy() *schema.Resource {
 	}
 }
 
+// resourceElasticsearchSnapshotLifecyclePolicyStateUpgradeV0 convert configs and retention JSON strings to blocks
+func resourceElasticsearchSnapshotLifecyclePolicyStateUpgradeV0(rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+
+	rawState["config"] = []interface{}{}
+	if configs, ok := rawState["configs"].(string); ok && configs != "" {
+		config := &SnapshotLifecyclePolicyConfig{}
+		if err := json.Unmarshal([]byte(configs), config); err != nil {
+			return nil, errors.Wrap(err, "Error when upgrade snapshot lifecycle policy configs")
+		}
+		rawState["config"] = flattenSnapshotLifecyclePolicyConfig(config)
+	}
+	delete(rawState, "configs")
+
+	retentionState := []interface{}{}
+	if retention, ok := rawState["retention"].(string); ok && retention != "" {
+		r := &SnapshotLifecyclePolicyRetention{}
+		if err := json.Unmarshal([]byte(retention), r); err != nil {
+			return nil, errors.Wrap(err, "Error when upgrade snapshot lifecycle policy retention")
+		}
+		retentionState = flattenSnapshotLifecyclePolicyRetention(r)
+	}
+	rawState["retention"] = retentionState
+
+	return rawState, nil
+}
+
 // resourceElasticsearchSnapshotLifecyclePolicyCreate create snapshot lifecycle policy
 func resourceElasticsearchSnapshotLifecyclePolicyCreate(d *schema.ResourceData, meta interface{}) error {
 
 	name := d.Get("name").(string)
 
+	if err := checkCreateOnly("Snapshot lifecycle policy", name, meta, func() (*esapi.Response, error) {
+		client := meta.(*providerConf).client
+		return client.API.SlmGetLifecycle(
+			client.API.SlmGetLifecycle.WithContext(context.Background()),
+			client.API.SlmGetLifecycle.WithPolicyID(name),
+		)
+	}); err != nil {
+		return err
+	}
+
 	err := createSnapshotLifecyclePolicy(d, meta)
 	if err != nil {
 		return err
@@ -92,9 +253,11 @@ func resourceElasticsearchSnapshotLifecyclePolicyCreate(d *schema.ResourceData,
 
 // resourceElasticsearchSnapshotLifecyclePolicyUpdate update snapshot lifecycle policy
 func resourceElasticsearchSnapshotLifecyclePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
-	err := createSnapshotLifecyclePolicy(d, meta)
-	if err != nil {
-		return err
+	if !onlyDeletionProtectionChanged(d, resourceElasticsearchSnapshotLifecyclePolicy().Schema) {
+		err := createSnapshotLifecyclePolicy(d, meta)
+		if err != nil {
+			return err
+		}
 	}
 	return resourceElasticsearchSnapshotLifecyclePolicyRead(d, meta)
 }
@@ -104,7 +267,7 @@ func resourceElasticsearchSnapshotLifecyclePolicyRead(d *schema.ResourceData, me
 
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.SlmGetLifecycle(
 		client.API.SlmGetLifecycle.WithContext(context.Background()),
 		client.API.SlmGetLifecycle.WithPretty(),
@@ -151,8 +314,8 @@ func resourceElasticsearchSnapshotLifecyclePolicyRead(d *schema.ResourceData, me
 	d.Set("snapshot_name", snapshotLifecyclePolicy[id].Policy.Name)
 	d.Set("schedule", snapshotLifecyclePolicy[id].Policy.Schedule)
 	d.Set("repository", snapshotLifecyclePolicy[id].Policy.Repository)
-	d.Set("configs", snapshotLifecyclePolicy[id].Policy.Configs)
-	d.Set("retention", snapshotLifecyclePolicy[id].Policy.Retention)
+	d.Set("config", flattenSnapshotLifecyclePolicyConfig(snapshotLifecyclePolicy[id].Policy.Configs))
+	d.Set("retention", flattenSnapshotLifecyclePolicyRetention(snapshotLifecyclePolicy[id].Policy.Retention))
 
 	return nil
 }
@@ -162,7 +325,11 @@ func resourceElasticsearchSnapshotLifecyclePolicyDelete(d *schema.ResourceData,
 
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	if err := checkDeletionProtection("Snapshot lifecycle policy", id, d); err != nil {
+		return err
+	}
+
+	client := meta.(*providerConf).client
 	res, err := client.API.SlmDeleteLifecycle(
 		id,
 		client.API.SlmDeleteLifecycle.WithContext(context.Background()),
@@ -196,8 +363,8 @@ func createSnapshotLifecyclePolicy(d *schema.ResourceData, meta interface{}) err
 	snapshotName := d.Get("snapshot_name").(string)
 	schedule := d.Get("schedule").(string)
 	repository := d.Get("repository").(string)
-	configs := optionalInterfaceJSON(d.Get("configs").(string))
-	retention := optionalInterfaceJSON(d.Get("retention").(string))
+	configs := buildSnapshotLifecyclePolicyConfig(d.Get("config").([]interface{}))
+	retention := buildSnapshotLifecyclePolicyRetention(d.Get("retention").([]interface{}), d.GetOkExists)
 
 	snapshotLifecyclePolicy := &SnapshotLifecyclePolicySpec{
 		Name:       snapshotName,
@@ -212,7 +379,7 @@ func createSnapshotLifecyclePolicy(d *schema.ResourceData, meta interface{}) err
 		return err
 	}
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 
 	res, err := client.API.SlmPutLifecycle(
 		name,
@@ -234,6 +401,109 @@ func createSnapshotLifecyclePolicy(d *schema.ResourceData, meta interface{}) err
 	return nil
 }
 
+// buildSnapshotLifecyclePolicyConfig convert config block to SnapshotLifecyclePolicyConfig object
+func buildSnapshotLifecyclePolicyConfig(raws []interface{}) *SnapshotLifecyclePolicyConfig {
+	if len(raws) == 0 || raws[0] == nil {
+		return nil
+	}
+	m := raws[0].(map[string]interface{})
+
+	ignoreUnavailable := m["ignore_unavailable"].(bool)
+	includeGlobalState := m["include_global_state"].(bool)
+	partial := m["partial"].(bool)
+
+	config := &SnapshotLifecyclePolicyConfig{
+		IgnoreUnavailable:  &ignoreUnavailable,
+		IncludeGlobalState: &includeGlobalState,
+		Partial:            &partial,
+		FeatureStates:      convertArrayInterfaceToArrayString(m["feature_states"].([]interface{})),
+		Metadata:           optionalInterfaceJSON(m["metadata"].(string)),
+	}
+	if indices := convertArrayInterfaceToArrayString(m["indices"].([]interface{})); len(indices) > 0 {
+		config.Indices = indices
+	}
+
+	return config
+}
+
+// flattenSnapshotLifecyclePolicyConfig convert SnapshotLifecyclePolicyConfig object to config block
+// Missing booleans are set with the Elasticsearch default value
+func flattenSnapshotLifecyclePolicyConfig(config *SnapshotLifecyclePolicyConfig) []interface{} {
+	if config == nil {
+		return []interface{}{}
+	}
+
+	var indices []string
+	switch v := config.Indices.(type) {
+	case string:
+		indices = strings.Split(v, ",")
+	case []interface{}:
+		indices = convertArrayInterfaceToArrayString(v)
+	case []string:
+		indices = v
+	}
+
+	metadata := "{}"
+	if config.Metadata != nil {
+		b, _ := json.Marshal(config.Metadata)
+		metadata = string(b)
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"indices":              indices,
+			"ignore_unavailable":   config.IgnoreUnavailable != nil && *config.IgnoreUnavailable,
+			"include_global_state": config.IncludeGlobalState == nil || *config.IncludeGlobalState,
+			"partial":              config.Partial != nil && *config.Partial,
+			"feature_states":       config.FeatureStates,
+			"metadata":             metadata,
+		},
+	}
+}
+
+// buildSnapshotLifecyclePolicyRetention convert retention block to SnapshotLifecyclePolicyRetention object
+// Counts are sent whenever they are set, even to 0
+func buildSnapshotLifecyclePolicyRetention(raws []interface{}, getOkExists func(string) (interface{}, bool)) *SnapshotLifecyclePolicyRetention {
+	if len(raws) == 0 || raws[0] == nil {
+		return nil
+	}
+	m := raws[0].(map[string]interface{})
+
+	retention := &SnapshotLifecyclePolicyRetention{
+		ExpireAfter: m["expire_after"].(string),
+	}
+	if v, ok := getOkExists("retention.0.min_count"); ok {
+		minCount := v.(int)
+		retention.MinCount = &minCount
+	}
+	if v, ok := getOkExists("retention.0.max_count"); ok {
+		maxCount := v.(int)
+		retention.MaxCount = &maxCount
+	}
+
+	return retention
+}
+
+// flattenSnapshotLifecyclePolicyRetention convert SnapshotLifecyclePolicyRetention object to retention block
+// Counts not set are left out of the block, so that they are not sent on next update
+func flattenSnapshotLifecyclePolicyRetention(retention *SnapshotLifecyclePolicyRetention) []interface{} {
+	if retention == nil {
+		return []interface{}{}
+	}
+
+	m := map[string]interface{}{
+		"expire_after": retention.ExpireAfter,
+	}
+	if retention.MinCount != nil {
+		m["min_count"] = *retention.MinCount
+	}
+	if retention.MaxCount != nil {
+		m["max_count"] = *retention.MaxCount
+	}
+
+	return []interface{}{m}
+}
+
 // Print snapshot lifecycle policy object as Json string
 func (r *SnapshotLifecyclePolicySpec) String() string {
 	json, _ := json.Marshal(r)
@@ -0,0 +1,180 @@
+package es
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+)
+
+func TestResourceElasticsearchSnapshotLifecyclePolicyStateUpgradeV0(t *testing.T) {
+	cases := []struct {
+		name     string
+		rawState map[string]interface{}
+		expected map[string]interface{}
+		isError  bool
+	}{
+		{
+			name: "configs and retention",
+			rawState: map[string]interface{}{
+				"id":            "terraform-test",
+				"name":          "terraform-test",
+				"snapshot_name": "<snap-{now/d}>",
+				"schedule":      "0 30 1 * * ?",
+				"repository":    "snapshot",
+				"configs":       `{"indices": ["data-*", "important"], "ignore_unavailable": true, "include_global_state": false, "partial": true, "feature_states": ["security"], "metadata": {"taken_by": "terraform"}}`,
+				"retention":     `{"expire_after": "30d", "min_count": 5, "max_count": 50}`,
+			},
+			expected: map[string]interface{}{
+				"id":            "terraform-test",
+				"name":          "terraform-test",
+				"snapshot_name": "<snap-{now/d}>",
+				"schedule":      "0 30 1 * * ?",
+				"repository":    "snapshot",
+				"config": []interface{}{
+					map[string]interface{}{
+						"indices":              []string{"data-*", "important"},
+						"ignore_unavailable":   true,
+						"include_global_state": false,
+						"partial":              true,
+						"feature_states":       []string{"security"},
+						"metadata":             `{"taken_by":"terraform"}`,
+					},
+				},
+				"retention": []interface{}{
+					map[string]interface{}{
+						"expire_after": "30d",
+						"min_count":    5,
+						"max_count":    50,
+					},
+				},
+			},
+		},
+		{
+			name: "configs with default values and comma separated indices",
+			rawState: map[string]interface{}{
+				"id":      "terraform-test",
+				"name":    "terraform-test",
+				"configs": `{"indices": "data-*,important"}`,
+			},
+			expected: map[string]interface{}{
+				"id":   "terraform-test",
+				"name": "terraform-test",
+				"config": []interface{}{
+					map[string]interface{}{
+						"indices":              []string{"data-*", "important"},
+						"ignore_unavailable":   false,
+						"include_global_state": true,
+						"partial":              false,
+						"feature_states":       []string(nil),
+						"metadata":             "{}",
+					},
+				},
+				"retention": []interface{}{},
+			},
+		},
+		{
+			name: "retention with explicit zero count",
+			rawState: map[string]interface{}{
+				"id":        "terraform-test",
+				"name":      "terraform-test",
+				"retention": `{"expire_after": "30d", "max_count": 0}`,
+			},
+			expected: map[string]interface{}{
+				"id":     "terraform-test",
+				"name":   "terraform-test",
+				"config": []interface{}{},
+				"retention": []interface{}{
+					map[string]interface{}{
+						"expire_after": "30d",
+						"max_count":    0,
+					},
+				},
+			},
+		},
+		{
+			name: "empty configs and retention",
+			rawState: map[string]interface{}{
+				"id":        "terraform-test",
+				"name":      "terraform-test",
+				"configs":   "",
+				"retention": "",
+			},
+			expected: map[string]interface{}{
+				"id":        "terraform-test",
+				"name":      "terraform-test",
+				"config":    []interface{}{},
+				"retention": []interface{}{},
+			},
+		},
+		{
+			name: "invalid configs",
+			rawState: map[string]interface{}{
+				"id":      "terraform-test",
+				"name":    "terraform-test",
+				"configs": `{"indices": [`,
+			},
+			isError: true,
+		},
+		{
+			name: "invalid retention",
+			rawState: map[string]interface{}{
+				"id":        "terraform-test",
+				"name":      "terraform-test",
+				"retention": `{"min_count": "five"}`,
+			},
+			isError: true,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := resourceElasticsearchSnapshotLifecyclePolicyStateUpgradeV0(c.rawState, nil)
+		if c.isError {
+			if err == nil {
+				t.Errorf("%s: expected error, got %+v", c.name, actual)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: error when upgrade state: %s", c.name, err)
+		}
+		if !reflect.DeepEqual(c.expected, actual) {
+			t.Errorf("%s: expected %+v, got %+v", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestBuildSnapshotLifecyclePolicyRetention(t *testing.T) {
+	cases := []struct {
+		name     string
+		raw      map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "explicit zero count",
+			raw:      map[string]interface{}{"expire_after": "30d", "min_count": 0},
+			expected: `{"expire_after":"30d","min_count":0}`,
+		},
+		{
+			name:     "counts not set",
+			raw:      map[string]interface{}{"expire_after": "30d"},
+			expected: `{"expire_after":"30d"}`,
+		},
+		{
+			name:     "counts set",
+			raw:      map[string]interface{}{"min_count": 5, "max_count": 50},
+			expected: `{"min_count":5,"max_count":50}`,
+		},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceElasticsearchSnapshotLifecyclePolicy().Schema, map[string]interface{}{
+			"name":      "terraform-test",
+			"retention": []interface{}{c.raw},
+		})
+		retention := buildSnapshotLifecyclePolicyRetention(d.Get("retention").([]interface{}), d.GetOkExists)
+		if actual := convertInterfaceToJSONString(retention); actual != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, actual)
+		}
+	}
+}
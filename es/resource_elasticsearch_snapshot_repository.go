@@ -13,7 +13,7 @@ import (
 	"fmt"
 	"io/ioutil"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
@@ -57,6 +57,7 @@ func resourceElasticsearchSnapshotRepository() *schema.Resource {
 					Type: schema.TypeString,
 				},
 			},
+			"deletion_protection": deletionProtectionSchema(),
 		},
 	}
 }
@@ -66,6 +67,16 @@ func resourceElasticsearchSnapshotRepositoryCreate(d *schema.ResourceData, meta
 
 	name := d.Get("name").(string)
 
+	if err := checkCreateOnly("Snapshot repository", name, meta, func() (*esapi.Response, error) {
+		client := meta.(*providerConf).client
+		return client.API.Snapshot.GetRepository(
+			client.API.Snapshot.GetRepository.WithContext(context.Background()),
+			client.API.Snapshot.GetRepository.WithRepository(name),
+		)
+	}); err != nil {
+		return err
+	}
+
 	err := createSnapshotRepository(d, meta)
 	if err != nil {
 		return err
@@ -76,9 +87,11 @@ func resourceElasticsearchSnapshotRepositoryCreate(d *schema.ResourceData, meta
 
 // resourceElasticsearchSnapshotRepositoryUpdate update the snapshot repository
 func resourceElasticsearchSnapshotRepositoryUpdate(d *schema.ResourceData, meta interface{}) error {
-	err := createSnapshotRepository(d, meta)
-	if err != nil {
-		return err
+	if !onlyDeletionProtectionChanged(d, resourceElasticsearchSnapshotRepository().Schema) {
+		err := createSnapshotRepository(d, meta)
+		if err != nil {
+			return err
+		}
 	}
 	return resourceElasticsearchSnapshotRepositoryRead(d, meta)
 }
@@ -88,7 +101,7 @@ func resourceElasticsearchSnapshotRepositoryRead(d *schema.ResourceData, meta in
 
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Snapshot.GetRepository(
 		client.API.Snapshot.GetRepository.WithContext(context.Background()),
 		client.API.Snapshot.GetRepository.WithPretty(),
@@ -133,7 +146,11 @@ func resourceElasticsearchSnapshotRepositoryDelete(d *schema.ResourceData, meta
 
 	id := d.Id()
 
-	client := meta.(*elastic.Client)
+	if err := checkDeletionProtection("Snapshot repository", id, d); err != nil {
+		return err
+	}
+
+	client := meta.(*providerConf).client
 	res, err := client.API.Snapshot.DeleteRepository(
 		[]string{id},
 		client.API.Snapshot.DeleteRepository.WithContext(context.Background()),
@@ -177,7 +194,7 @@ func createSnapshotRepository(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 
 	res, err := client.API.Snapshot.CreateRepository(
 		name,
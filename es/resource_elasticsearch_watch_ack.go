@@ -0,0 +1,146 @@
+// Acknowledge watch actions in Elasticsearch
+// API documentation: https://www.elastic.co/guide/en/elasticsearch/reference/current/watcher-api-ack-watch.html
+// Supported version:
+//  - v6
+//  - v7
+
+package es
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// resourceElasticsearchWatchAck handle the ack watch API call
+// Elasticsearch not provide API to cancel acknowledgement, so delete only remove it from state.
+// The acknowledgement is reset by Elasticsearch when the watch condition is not met anymore.
+func resourceElasticsearchWatchAck() *schema.Resource {
+	return &schema.Resource{
+		Create: resourceElasticsearchWatchAckCreate,
+		Read:   resourceElasticsearchWatchAckRead,
+		Update: resourceElasticsearchWatchAckUpdate,
+		Delete: resourceElasticsearchWatchAckDelete,
+
+		Schema: map[string]*schema.Schema{
+			"watch_id": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+			},
+			"actions": {
+				Type:     schema.TypeSet,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: "Arbitrary values that acknowledge again the actions when they change",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"ack_states": {
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+// resourceElasticsearchWatchAckCreate acknowledge the watch actions
+func resourceElasticsearchWatchAckCreate(d *schema.ResourceData, meta interface{}) error {
+	watchID := d.Get("watch_id").(string)
+
+	err := ackWatch(d, meta)
+	if err != nil {
+		return err
+	}
+	d.SetId(watchID)
+
+	log.Infof("Acknowledged watch %s successfully", watchID)
+
+	return resourceElasticsearchWatchAckRead(d, meta)
+}
+
+// resourceElasticsearchWatchAckRead read the acknowledgement state of the watch actions
+func resourceElasticsearchWatchAckRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	watcher, err := getWatcher(id, meta.(*providerConf).client)
+	if err != nil {
+		return err
+	}
+	if watcher == nil {
+		log.Warnf("Watcher %s not found - removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	ackStates := make(map[string]string)
+	if watcher.Status != nil {
+		for _, action := range convertArrayInterfaceToArrayString(d.Get("actions").(*schema.Set).List()) {
+			if actionStatus, ok := watcher.Status.Actions[action]; ok && actionStatus.Ack != nil {
+				ackStates[action] = actionStatus.Ack.State
+			}
+		}
+	}
+
+	d.Set("watch_id", id)
+	d.Set("ack_states", ackStates)
+
+	return nil
+}
+
+// resourceElasticsearchWatchAckUpdate acknowledge again the watch actions
+func resourceElasticsearchWatchAckUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := ackWatch(d, meta)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Acknowledged watch %s successfully", d.Id())
+
+	return resourceElasticsearchWatchAckRead(d, meta)
+}
+
+// resourceElasticsearchWatchAckDelete only remove the acknowledgement from state
+func resourceElasticsearchWatchAckDelete(d *schema.ResourceData, meta interface{}) error {
+	log.Warnf("Acknowledgement of watch %s can't be canceled, it will be reset when the watch condition is not met", d.Id())
+	d.SetId("")
+	return nil
+}
+
+// ackWatch acknowledge the watch actions
+func ackWatch(d *schema.ResourceData, meta interface{}) error {
+	watchID := d.Get("watch_id").(string)
+	actions := convertArrayInterfaceToArrayString(d.Get("actions").(*schema.Set).List())
+
+	client := meta.(*providerConf).client
+	res, err := client.API.Watcher.AckWatch(
+		watchID,
+		client.API.Watcher.AckWatch.WithActionID(actions...),
+		client.API.Watcher.AckWatch.WithContext(context.Background()),
+		client.API.Watcher.AckWatch.WithPretty(),
+	)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return errors.Errorf("Error when acknowledge watch %s: %s", watchID, res.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
+)
+
+func TestAccElasticsearchWatchAck(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchWatcherDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchWatchAck,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_watch_ack.test", "id", "terraform-test"),
+					resource.TestCheckResourceAttrSet("elasticsearch_watch_ack.test", "ack_states.log_error"),
+				),
+			},
+		},
+	})
+}
+
+var testElasticsearchWatchAck = `
+resource "elasticsearch_watcher" "test" {
+  name = "terraform-test"
+
+  schedule {
+    interval = "10m"
+  }
+
+  action {
+    name = "log_error"
+    logging {
+      text = "test"
+    }
+  }
+}
+
+resource "elasticsearch_watch_ack" "test" {
+  watch_id = elasticsearch_watcher.test.id
+  actions  = ["log_error"]
+}
+`
@@ -12,26 +12,129 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	elastic "github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
+// watcherTypedBlocks are the typed blocks used to build each JSON attribute
+var watcherTypedBlocks = map[string][]string{
+	"trigger":   {"schedule"},
+	"input":     {"search_input", "http_input"},
+	"condition": {"compare_condition", "script_condition"},
+	"actions":   {"action"},
+}
+
+// watcherJSONRules are the defaults added by Elasticsearch on watch trigger, input, condition and actions
+var watcherJSONRules = &canonical.Rules{
+	Defaults: map[string]interface{}{
+		"search.request.search_type":            "query_then_fetch",
+		"search.request.rest_total_hits_as_int": true,
+		"script.lang":                           "painless",
+		"*.logging.level":                       "info",
+	},
+}
+
 // Watcher object returned by API
 type Watcher struct {
-	Watcher *WatcherSpec `json:"watch"`
+	Watcher *WatcherSpec   `json:"watch"`
+	Status  *WatcherStatus `json:"status,omitempty"`
 }
 
 // WatcherSpec is the watcher object
 type WatcherSpec struct {
-	Trigger        interface{} `json:"trigger,omitempty"`
-	Input          interface{} `json:"input,omitempty"`
-	Condition      interface{} `json:"condition,omitempty"`
-	Actions        interface{} `json:"actions,omitempty"`
-	Metadata       interface{} `json:"metadata,omitempty"`
-	ThrottlePeriod string      `json:"throttle_period,omitempty"`
+	Trigger                interface{} `json:"trigger,omitempty"`
+	Input                  interface{} `json:"input,omitempty"`
+	Condition              interface{} `json:"condition,omitempty"`
+	Actions                interface{} `json:"actions,omitempty"`
+	Transform              interface{} `json:"transform,omitempty"`
+	Metadata               interface{} `json:"metadata,omitempty"`
+	ThrottlePeriod         string      `json:"throttle_period,omitempty"`
+	ThrottlePeriodInMillis int64       `json:"throttle_period_in_millis,omitempty"`
+}
+
+// WatcherStatus is the watcher status returned by API
+type WatcherStatus struct {
+	State            *WatcherState                   `json:"state,omitempty"`
+	LastChecked      string                          `json:"last_checked,omitempty"`
+	LastMetCondition string                          `json:"last_met_condition,omitempty"`
+	ExecutionState   string                          `json:"execution_state,omitempty"`
+	Version          int64                           `json:"version,omitempty"`
+	Actions          map[string]*WatcherActionStatus `json:"actions,omitempty"`
+}
+
+// WatcherState is the watcher state
+type WatcherState struct {
+	Active    bool   `json:"active"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// WatcherActionStatus is the status of one watch action
+type WatcherActionStatus struct {
+	Ack                     *WatcherActionAck       `json:"ack,omitempty"`
+	LastExecution           *WatcherActionExecution `json:"last_execution,omitempty"`
+	LastSuccessfulExecution *WatcherActionExecution `json:"last_successful_execution,omitempty"`
+	LastThrottle            *WatcherActionExecution `json:"last_throttle,omitempty"`
+}
+
+// WatcherActionAck is the acknowledgement state of action
+type WatcherActionAck struct {
+	State     string `json:"state"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// WatcherActionExecution is the execution or throttle of action
+type WatcherActionExecution struct {
+	Timestamp  string `json:"timestamp,omitempty"`
+	Successful bool   `json:"successful"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// WatcherExecuteSpec is the execute watch request
+type WatcherExecuteSpec struct {
+	Watch            *WatcherSpec      `json:"watch,omitempty"`
+	RecordExecution  bool              `json:"record_execution"`
+	IgnoreCondition  bool              `json:"ignore_condition,omitempty"`
+	AlternativeInput interface{}       `json:"alternative_input,omitempty"`
+	ActionModes      map[string]string `json:"action_modes,omitempty"`
+}
+
+// WatcherExecute object returned by execute watch API
+type WatcherExecute struct {
+	WatchRecord *WatcherExecuteRecord `json:"watch_record"`
+}
+
+// WatcherExecuteRecord is the watch record of the execution
+type WatcherExecuteRecord struct {
+	State    string                `json:"state"`
+	Messages []string              `json:"messages,omitempty"`
+	Result   *WatcherExecuteResult `json:"result,omitempty"`
+}
+
+// WatcherExecuteResult is the result of each execution step
+type WatcherExecuteResult struct {
+	Input     *WatcherExecuteStepResult  `json:"input,omitempty"`
+	Condition *WatcherExecuteStepResult  `json:"condition,omitempty"`
+	Transform *WatcherExecuteStepResult  `json:"transform,omitempty"`
+	Actions   []WatcherExecuteStepResult `json:"actions,omitempty"`
+}
+
+// WatcherExecuteStepResult is the result of input, condition, transform or action
+type WatcherExecuteStepResult struct {
+	ID     string      `json:"id,omitempty"`
+	Type   string      `json:"type,omitempty"`
+	Status string      `json:"status"`
+	Reason string      `json:"reason,omitempty"`
+	Error  interface{} `json:"error,omitempty"`
 }
 
 // resourceElasticsearchWatcher handle the watcher API call
@@ -42,8 +145,16 @@ func resourceElasticsearchWatcher() *schema.Resource {
 		Update: resourceElasticsearchWatcherUpdate,
 		Delete: resourceElasticsearchWatcherDelete,
 
+		CustomizeDiff: resourceElasticsearchWatcherCustomizeDiff,
+
 		Importer: &schema.ResourceImporter{
-			State: schema.ImportStatePassthrough,
+			State: importStateManagedBy("Watcher", func(id string, meta interface{}) (map[string]interface{}, error) {
+				watcher, err := getWatcher(id, meta.(*providerConf).client)
+				if err != nil || watcher == nil || watcher.Watcher == nil {
+					return nil, err
+				}
+				return getManagedBy(watcher.Watcher.Metadata, nil), nil
+			}),
 		},
 
 		Schema: map[string]*schema.Schema{
@@ -55,37 +166,509 @@ func resourceElasticsearchWatcher() *schema.Resource {
 			"trigger": {
 				Type:             schema.TypeString,
 				Optional:         true,
-				DiffSuppressFunc: suppressEquivalentJSON,
+				Computed:         true,
+				ConflictsWith:    []string{"schedule"},
+				DiffSuppressFunc: suppressEquivalentWatcherJSON,
+				StateFunc:        canonicalJSONStateFunc(watcherJSONRules),
+			},
+			"schedule": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"trigger"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"interval": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateElasticsearchDuration,
+						},
+						"cron": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validateElasticsearchCron,
+							},
+						},
+						"daily": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"at": {
+										Type:     schema.TypeList,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+								},
+							},
+						},
+						"hourly": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"minute": {
+										Type:     schema.TypeList,
+										Required: true,
+										Elem: &schema.Schema{
+											Type:         schema.TypeInt,
+											ValidateFunc: validation.IntBetween(0, 59),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"search_input": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"input", "http_input"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"indices": {
+							Type:     schema.TypeList,
+							Required: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"body": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+						},
+						"search_type": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringInSlice([]string{"query_then_fetch", "dfs_query_then_fetch"}, false),
+						},
+						"timeout": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateElasticsearchDuration,
+						},
+					},
+				},
+			},
+			"http_input": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"input", "search_input"},
+				Elem: &schema.Resource{
+					Schema: watcherHTTPRequestSchema(),
+				},
+			},
+			"compare_condition": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"condition", "script_condition"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"operator": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"eq", "not_eq", "gt", "gte", "lt", "lte"}, false),
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "JSON value to compare with, other strings are compared as is",
+						},
+					},
+				},
+			},
+			"script_condition": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"condition", "compare_condition"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"source": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"lang": {
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"params": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+						},
+					},
+				},
+			},
+			"action": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ConflictsWith: []string{"actions"},
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"throttle_period": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validateElasticsearchDuration,
+							DiffSuppressFunc: suppressEquivalentDuration,
+						},
+						"condition": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+						},
+						"email": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"to": {
+										Type:     schema.TypeList,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"subject": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"body": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"webhook": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: watcherHTTPRequestSchema(),
+							},
+						},
+						"slack": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"account": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+									"to": {
+										Type:     schema.TypeList,
+										Required: true,
+										Elem: &schema.Schema{
+											Type: schema.TypeString,
+										},
+									},
+									"text": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+								},
+							},
+						},
+						"index": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"index": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"doc_id": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+						"logging": {
+							Type:     schema.TypeList,
+							Optional: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"text": {
+										Type:     schema.TypeString,
+										Required: true,
+									},
+									"level": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										ValidateFunc: validation.StringInSlice([]string{"error", "warn", "info", "debug", "trace"}, false),
+									},
+									"category": {
+										Type:     schema.TypeString,
+										Optional: true,
+									},
+								},
+							},
+						},
+					},
+				},
 			},
 			"input": {
 				Type:             schema.TypeString,
 				Optional:         true,
-				DiffSuppressFunc: suppressEquivalentJSON,
+				Computed:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"search_input", "http_input"},
+				DiffSuppressFunc: suppressEquivalentWatcherJSON,
+				StateFunc:        canonicalJSONStateFunc(watcherJSONRules),
 			},
 			"condition": {
 				Type:             schema.TypeString,
 				Optional:         true,
-				DiffSuppressFunc: suppressEquivalentJSON,
+				Computed:         true,
+				ConflictsWith:    []string{"compare_condition", "script_condition"},
+				DiffSuppressFunc: suppressEquivalentWatcherJSON,
+				StateFunc:        canonicalJSONStateFunc(watcherJSONRules),
 			},
 			"actions": {
 				Type:             schema.TypeString,
 				Optional:         true,
-				DiffSuppressFunc: suppressEquivalentJSON,
+				Computed:         true,
+				Sensitive:        true,
+				ConflictsWith:    []string{"action"},
+				DiffSuppressFunc: suppressEquivalentWatcherJSON,
+				StateFunc:        canonicalJSONStateFunc(watcherJSONRules),
+			},
+			"secrets": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Secret values merged into actions, keys are dotted paths like my_webhook.webhook.auth.basic.password",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"transform": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentWatcherJSON,
+				StateFunc:        canonicalJSONStateFunc(watcherJSONRules),
 			},
 			"metadata": {
 				Type:             schema.TypeString,
 				Optional:         true,
 				DiffSuppressFunc: suppressEquivalentJSON,
+				StateFunc:        canonicalJSONStateFunc(nil),
 			},
 			"throttle_period": {
 				Type:             schema.TypeString,
 				Optional:         true,
-				DiffSuppressFunc: suppressEquivalentJSON,
+				ValidateFunc:     validateElasticsearchDuration,
+				DiffSuppressFunc: suppressEquivalentDuration,
+			},
+			"active": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"test_execution": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Execute the watch without record it after each create or update",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"alternative_input": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJSON,
+							StateFunc:        canonicalJSONStateFunc(nil),
+						},
+						"action_modes": {
+							Type:         schema.TypeMap,
+							Optional:     true,
+							ValidateFunc: validateWatcherActionModes,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"ignore_condition": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"fail_on_error": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     true,
+							Description: "Fail the apply if execution failed, else only log a warning",
+						},
+					},
+				},
+			},
+			"managed_by": managedBySchema(),
+			"takeover":   takeoverSchema(),
+		},
+	}
+}
+
+// watcherHTTPRequestSchema is the HTTP request schema shared by http input and webhook action
+func watcherHTTPRequestSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"scheme": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "http",
+			ValidateFunc: validation.StringInSlice([]string{"http", "https"}, false),
+		},
+		"host": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"port": {
+			Type:         schema.TypeInt,
+			Required:     true,
+			ValidateFunc: validation.IsPortNumber,
+		},
+		"method": {
+			Type:         schema.TypeString,
+			Optional:     true,
+			Default:      "get",
+			ValidateFunc: validation.StringInSlice([]string{"head", "get", "post", "put", "delete"}, false),
+		},
+		"path": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"params": {
+			Type:     schema.TypeMap,
+			Optional: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"headers": {
+			Type:      schema.TypeMap,
+			Optional:  true,
+			Sensitive: true,
+			Elem: &schema.Schema{
+				Type: schema.TypeString,
+			},
+		},
+		"body": {
+			Type:     schema.TypeString,
+			Optional: true,
+		},
+		"basic_auth": {
+			Type:     schema.TypeList,
+			Optional: true,
+			MaxItems: 1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"username": {
+						Type:     schema.TypeString,
+						Required: true,
+					},
+					"password": {
+						Type:      schema.TypeString,
+						Required:  true,
+						Sensitive: true,
+					},
+				},
 			},
 		},
 	}
 }
 
+// resourceElasticsearchWatcherCustomizeDiff check typed blocks and set JSON attributes from them when they change
+// or when the watch was changed outside of Terraform
+func resourceElasticsearchWatcherCustomizeDiff(d *schema.ResourceDiff, meta interface{}) error {
+
+	for _, raw := range d.Get("schedule").([]interface{}) {
+		if raw == nil {
+			return errors.New("Schedule must have one of interval, cron, daily or hourly")
+		}
+		if nb := len(blockTypes(raw.(map[string]interface{}), "interval", "cron", "daily", "hourly")); nb != 1 {
+			return errors.Errorf("Schedule must have exactly one of interval, cron, daily or hourly, got %d", nb)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, raw := range d.Get("action").([]interface{}) {
+		action := raw.(map[string]interface{})
+		name := action["name"].(string)
+		if names[name] {
+			return errors.Errorf("Action %s is declared more than once", name)
+		}
+		names[name] = true
+		if nb := len(blockTypes(action, "email", "webhook", "slack", "index", "logging")); nb != 1 {
+			return errors.Errorf("Action %s must have exactly one of email, webhook, slack, index or logging, got %d", name, nb)
+		}
+	}
+
+	// The JSON read from the API is compared with the JSON built from typed blocks to detect changes made outside of Terraform
+	for attribute, blocks := range watcherTypedBlocks {
+		hasChange := false
+		known := true
+		for _, block := range blocks {
+			if d.HasChange(block) {
+				hasChange = true
+			}
+			if !d.NewValueKnown(block) {
+				known = false
+			}
+		}
+		if !known {
+			if err := d.SetNewComputed(attribute); err != nil {
+				return err
+			}
+			continue
+		}
+
+		value := buildWatcherTypedAttribute(attribute, d.Get)
+		if value == nil {
+			continue
+		}
+		current, _ := d.GetChange(attribute)
+		if !hasChange && equivalentWatcherJSON(current.(string), value) {
+			continue
+		}
+		if err := d.SetNew(attribute, canonicalJSONStateFunc(watcherJSONRules)(convertInterfaceToJSONString(value))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // resourceElasticsearchWatcherCreate create new watcher in Elasticsearch
 func resourceElasticsearchWatcherCreate(d *schema.ResourceData, meta interface{}) error {
 	name := d.Get("name").(string)
@@ -108,62 +691,108 @@ func resourceElasticsearchWatcherRead(d *schema.ResourceData, meta interface{})
 
 	log.Debugf("Watcher id:  %s", id)
 
-	client := meta.(*elastic.Client)
+	watcher, err := getWatcher(id, meta.(*providerConf).client)
+	if err != nil {
+		return err
+	}
+	if watcher == nil {
+		fmt.Printf("[WARN] Watcher %s not found - removing from state", id)
+		log.Warnf("Watcher %s not found - removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	watcherSpec := watcher.Watcher
+
+	log.Debugf("Watcher %+v", watcherSpec)
+
+	// API return throttle period in millis except when human flag is used
+	throttlePeriod := watcherSpec.ThrottlePeriod
+	if throttlePeriod == "" && watcherSpec.ThrottlePeriodInMillis > 0 {
+		throttlePeriod = fmt.Sprintf("%dms", watcherSpec.ThrottlePeriodInMillis)
+	}
+
+	d.Set("name", id)
+	d.Set("trigger", convertInterfaceToJSONString(watcherSpec.Trigger))
+	d.Set("input", convertInterfaceToJSONString(watcherSpec.Input))
+	d.Set("condition", convertInterfaceToJSONString(watcherSpec.Condition))
+	d.Set("actions", convertInterfaceToJSONString(watcherSpec.Actions))
+	d.Set("transform", convertInterfaceToJSONString(watcherSpec.Transform))
+	d.Set("metadata", convertInterfaceToJSONString(removeManagedBy(watcherSpec.Metadata)))
+	d.Set("managed_by", getManagedBy(watcherSpec.Metadata, nil))
+	d.Set("throttle_period", throttlePeriod)
+	if watcher.Status != nil && watcher.Status.State != nil {
+		d.Set("active", watcher.Status.State.Active)
+	}
+
+	log.Infof("Read watcher %s successfully", id)
+
+	return nil
+}
+
+// getWatcher get watch and its status from Elasticsearch
+// It return nil if the watch not exist
+func getWatcher(id string, client *elastic.Client) (*Watcher, error) {
 	res, err := client.API.Watcher.GetWatch(
 		id,
 		client.API.Watcher.GetWatch.WithContext(context.Background()),
 		client.API.Watcher.GetWatch.WithPretty(),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer res.Body.Close()
 	if res.IsError() {
 		if res.StatusCode == 404 {
-			fmt.Printf("[WARN] Watcher %s not found - removing from state", id)
-			log.Warnf("Watcher %s not found - removing from state", id)
-			d.SetId("")
-			return nil
+			return nil, nil
 		}
-		return errors.Errorf("Error when get watcher %s: %s", id, res.String())
+		return nil, errors.Errorf("Error when get watcher %s: %s", id, res.String())
 
 	}
 	b, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	log.Debugf("Get watcher %s successfully:\n%s", id, string(b))
 	watcher := &Watcher{}
 	err = json.Unmarshal(b, watcher)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	watcherSpec := watcher.Watcher
-
-	log.Debugf("Watcher %+v", watcherSpec)
-
-	d.Set("name", id)
-	d.Set("trigger", watcherSpec.Trigger)
-	d.Set("input", watcherSpec.Trigger)
-	d.Set("condition", watcherSpec.Condition)
-	d.Set("actions", watcherSpec.Actions)
-	d.Set("metadata", watcherSpec.Metadata)
-	d.Set("throttle_period", watcherSpec.ThrottlePeriod)
-
-	log.Infof("Read watcher %s successfully", id)
-
-	return nil
+	return watcher, nil
 }
 
 // resourceElasticsearchWatcherUpdate update existing watcher in Elasticsearch
+// When only active flag change, it use activate / deactivate API instead to put the watch again
+// The state is kept unchanged until the watch is tested and put
 func resourceElasticsearchWatcherUpdate(d *schema.ResourceData, meta interface{}) error {
-	err := createWatcher(d, meta)
-	if err != nil {
-		return err
+	d.Partial(true)
+
+	if d.HasChange("trigger") || d.HasChange("input") || d.HasChange("condition") || d.HasChange("actions") || d.HasChange("transform") || d.HasChange("metadata") || d.HasChange("throttle_period") ||
+		d.HasChange("schedule") || d.HasChange("search_input") || d.HasChange("http_input") || d.HasChange("compare_condition") || d.HasChange("script_condition") || d.HasChange("action") || d.HasChange("secrets") || d.HasChange("managed_by") {
+		err := createWatcher(d, meta)
+		if err != nil {
+			return err
+		}
+	} else {
+		watcher, err := buildWatcher(d)
+		if err != nil {
+			return err
+		}
+		if err := testWatcherExecution(d, meta, watcher); err != nil {
+			return err
+		}
+		if d.HasChange("active") {
+			if err := activateWatcher(d.Id(), d.Get("active").(bool), meta); err != nil {
+				return err
+			}
+		}
 	}
 
+	d.Partial(false)
+
 	log.Infof("Updated watcher %s successfully", d.Id())
 
 	return resourceElasticsearchWatcherRead(d, meta)
@@ -175,7 +804,7 @@ func resourceElasticsearchWatcherDelete(d *schema.ResourceData, meta interface{}
 	id := d.Id()
 	log.Debugf("Watcher id: %s", id)
 
-	client := meta.(*elastic.Client)
+	client := meta.(*providerConf).client
 	res, err := client.API.Watcher.DeleteWatch(
 		id,
 		client.API.Watcher.DeleteWatch.WithContext(context.Background()),
@@ -212,24 +841,85 @@ func (r *WatcherSpec) String() string {
 	return string(json)
 }
 
-// createWatcher create or update watcher in Elasticsearch
-func createWatcher(d *schema.ResourceData, meta interface{}) error {
+// buildWatcher convert attributes to watcher object
+// Typed blocks take precedence over computed JSON attributes and secrets are merged in actions
+func buildWatcher(d *schema.ResourceData) (*WatcherSpec, error) {
 	name := d.Get("name").(string)
 	trigger := optionalInterfaceJSON(d.Get("trigger").(string))
 	input := optionalInterfaceJSON(d.Get("input").(string))
 	condition := optionalInterfaceJSON(d.Get("condition").(string))
 	actions := optionalInterfaceJSON(d.Get("actions").(string))
-	metadata := optionalInterfaceJSON(d.Get("metadata").(string))
-	throttlePeriod := d.Get("throttle_period").(string)
+
+	if value := buildWatcherTypedAttribute("trigger", d.Get); value != nil {
+		trigger = value
+	}
+	if value := buildWatcherTypedAttribute("input", d.Get); value != nil {
+		input = value
+	}
+	if value := buildWatcherTypedAttribute("condition", d.Get); value != nil {
+		condition = value
+	}
+	if value := buildWatcherTypedAttribute("actions", d.Get); value != nil {
+		actions = value
+	}
+	secrets := convertMapInterfaceToMapString(d.Get("secrets").(map[string]interface{}))
+	if err := checkWatcherRedactedValues(name, input, actions, secrets); err != nil {
+		return nil, err
+	}
+	if len(secrets) > 0 {
+		var err error
+		actions, err = mergeWatcherSecrets(actions, secrets)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	metadata, err := stampManagedBy(d.Get("metadata").(string), nil, d.Get("managed_by").(map[string]interface{}))
+	if err != nil {
+		return nil, err
+	}
 
 	watcher := &WatcherSpec{
 		Trigger:        trigger,
 		Input:          input,
 		Condition:      condition,
 		Actions:        actions,
-		Metadata:       metadata,
-		ThrottlePeriod: throttlePeriod,
+		Transform:      optionalInterfaceJSON(d.Get("transform").(string)),
+		Metadata:       optionalInterfaceJSON(metadata),
+		ThrottlePeriod: d.Get("throttle_period").(string),
+	}
+
+	return watcher, nil
+}
+
+// createWatcher create or update watcher in Elasticsearch
+// The watch is test executed before being put, so that a failed test doesn't change the watch
+func createWatcher(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	active := d.Get("active").(bool)
+	client := meta.(*providerConf).client
+
+	current, err := getWatcher(name, client)
+	if err != nil {
+		return err
+	}
+	if current != nil && isCreateOnly(d, meta) {
+		return alreadyExistError("Watcher", name)
+	}
+	if current != nil && current.Watcher != nil {
+		if err := checkManagedBy("Watcher", name, getManagedBy(current.Watcher.Metadata, nil), d); err != nil {
+			return err
+		}
 	}
+
+	watcher, err := buildWatcher(d)
+	if err != nil {
+		return err
+	}
+	if err := testWatcherExecution(d, meta, watcher); err != nil {
+		return err
+	}
+
 	log.Debug("Name: ", name)
 	log.Debug("Watcher: ", watcher)
 
@@ -238,10 +928,10 @@ func createWatcher(d *schema.ResourceData, meta interface{}) error {
 		return err
 	}
 
-	client := meta.(*elastic.Client)
 	res, err := client.API.Watcher.PutWatch(
 		name,
 		client.API.Watcher.PutWatch.WithBody(bytes.NewReader(data)),
+		client.API.Watcher.PutWatch.WithActive(active),
 		client.API.Watcher.PutWatch.WithContext(context.Background()),
 		client.API.Watcher.PutWatch.WithPretty(),
 	)
@@ -258,3 +948,593 @@ func createWatcher(d *schema.ResourceData, meta interface{}) error {
 
 	return nil
 }
+
+// checkWatcherRedactedValues check the input and actions sent to Elasticsearch not contain secrets redacted by Elasticsearch
+// Redacted values come from state when the diff is suppressed, putting them would overwrite the real secrets.
+// Redacted values in actions are sent only if secrets don't fill their path.
+func checkWatcherRedactedValues(name string, input interface{}, actions interface{}, secrets map[string]string) error {
+	if paths := getRedactedPaths(input); len(paths) > 0 {
+		return errors.Errorf("Watcher %s input contains secret %s redacted by Elasticsearch, change it in input to force its update", name, paths[0])
+	}
+	for _, path := range getRedactedPaths(actions) {
+		if _, ok := secrets[path]; !ok {
+			return errors.Errorf("Watcher %s actions contains secret %s redacted by Elasticsearch, provide it with secrets attribute or change it in actions to force its update", name, path)
+		}
+	}
+
+	return nil
+}
+
+// getRedactedPaths return the sorted dotted paths of redacted values in JSON object, list items are indexed by their position
+func getRedactedPaths(value interface{}) []string {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(convertInterfaceToJSONString(value)), &decoded); err != nil {
+		return nil
+	}
+
+	paths := make([]string, 0)
+	var walk func(value interface{}, path string)
+	walk = func(value interface{}, path string) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				walk(child, strings.TrimPrefix(path+"."+key, "."))
+			}
+		case []interface{}:
+			for i, child := range v {
+				walk(child, strings.TrimPrefix(fmt.Sprintf("%s.%d", path, i), "."))
+			}
+		case string:
+			if v == redactedValue {
+				paths = append(paths, path)
+			}
+		}
+	}
+	walk(decoded, "")
+	sort.Strings(paths)
+
+	return paths
+}
+
+// activateWatcher activate or deactivate watcher in Elasticsearch
+func activateWatcher(name string, active bool, meta interface{}) error {
+	client := meta.(*providerConf).client
+
+	var res *esapi.Response
+	var err error
+	if active {
+		res, err = client.API.Watcher.ActivateWatch(
+			name,
+			client.API.Watcher.ActivateWatch.WithContext(context.Background()),
+			client.API.Watcher.ActivateWatch.WithPretty(),
+		)
+	} else {
+		res, err = client.API.Watcher.DeactivateWatch(
+			name,
+			client.API.Watcher.DeactivateWatch.WithContext(context.Background()),
+			client.API.Watcher.DeactivateWatch.WithPretty(),
+		)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return errors.Errorf("Error when change active state of watcher %s: %s", name, res.String())
+	}
+
+	log.Infof("Set active state of watcher %s to %t successfully", name, active)
+
+	return nil
+}
+
+// testWatcherExecution execute the inline watch without record it when test_execution is set
+// It check the input, condition, transform and actions result
+func testWatcherExecution(d *schema.ResourceData, meta interface{}, watcher *WatcherSpec) error {
+	raws := d.Get("test_execution").([]interface{})
+	if len(raws) == 0 {
+		return nil
+	}
+
+	name := d.Get("name").(string)
+	testExecution := make(map[string]interface{})
+	if raws[0] != nil {
+		testExecution = raws[0].(map[string]interface{})
+	}
+	failOnError := true
+	if v, ok := testExecution["fail_on_error"].(bool); ok {
+		failOnError = v
+	}
+
+	executeSpec := &WatcherExecuteSpec{
+		Watch:           watcher,
+		RecordExecution: false,
+	}
+	if v, ok := testExecution["alternative_input"].(string); ok {
+		executeSpec.AlternativeInput = optionalInterfaceJSON(v)
+	}
+	if v, ok := testExecution["action_modes"].(map[string]interface{}); ok && len(v) > 0 {
+		executeSpec.ActionModes = convertMapInterfaceToMapString(v)
+	}
+	if v, ok := testExecution["ignore_condition"].(bool); ok {
+		executeSpec.IgnoreCondition = v
+	}
+
+	data, err := json.Marshal(executeSpec)
+	if err != nil {
+		return err
+	}
+
+	client := meta.(*providerConf).client
+	res, err := client.API.Watcher.ExecuteWatch(
+		client.API.Watcher.ExecuteWatch.WithBody(bytes.NewReader(data)),
+		client.API.Watcher.ExecuteWatch.WithContext(context.Background()),
+		client.API.Watcher.ExecuteWatch.WithPretty(),
+	)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return errors.Errorf("Error when execute watcher %s: %s", name, res.String())
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Execute watcher %s successfully:\n%s", name, string(b))
+
+	watcherExecute := &WatcherExecute{}
+	err = json.Unmarshal(b, watcherExecute)
+	if err != nil {
+		return err
+	}
+
+	failures := watcherExecutionFailures(watcherExecute.WatchRecord)
+	if len(failures) == 0 {
+		log.Infof("Test execution of watcher %s successfully", name)
+		return nil
+	}
+
+	if failOnError {
+		return errors.Errorf("Test execution of watcher %s failed:\n%s", name, strings.Join(failures, "\n"))
+	}
+	log.Warnf("Test execution of watcher %s failed:\n%s", name, strings.Join(failures, "\n"))
+
+	return nil
+}
+
+// watcherExecutionFailures return the human readable list of failures in watch record
+func watcherExecutionFailures(record *WatcherExecuteRecord) []string {
+	failures := make([]string, 0)
+	if record == nil {
+		return failures
+	}
+
+	if record.State == "failed" {
+		failures = append(failures, fmt.Sprintf("watch state is %s: %s", record.State, strings.Join(record.Messages, ", ")))
+	}
+	if record.Result == nil {
+		return failures
+	}
+
+	steps := map[string]*WatcherExecuteStepResult{
+		"input":     record.Result.Input,
+		"condition": record.Result.Condition,
+		"transform": record.Result.Transform,
+	}
+	for _, step := range []string{"input", "condition", "transform"} {
+		if result := steps[step]; result != nil && result.Status == "failure" {
+			failures = append(failures, fmt.Sprintf("%s failed: %s", step, watcherExecuteStepReason(result)))
+		}
+	}
+	for _, action := range record.Result.Actions {
+		if action.Status == "failure" {
+			failures = append(failures, fmt.Sprintf("action %s failed: %s", action.ID, watcherExecuteStepReason(&action)))
+		}
+	}
+
+	return failures
+}
+
+// watcherExecuteStepReason return the reason or the error of failed step
+func watcherExecuteStepReason(result *WatcherExecuteStepResult) string {
+	if result.Reason != "" {
+		return result.Reason
+	}
+	return convertInterfaceToJSONString(result.Error)
+}
+
+// buildWatcherTypedAttribute convert the typed blocks of JSON attribute to its object
+// It return nil if none of typed blocks is set
+func buildWatcherTypedAttribute(attribute string, get func(string) interface{}) interface{} {
+	for _, block := range watcherTypedBlocks[attribute] {
+		raws := get(block).([]interface{})
+		if len(raws) == 0 {
+			continue
+		}
+
+		switch block {
+		case "schedule":
+			return buildWatcherSchedule(raws)
+		case "search_input":
+			return buildWatcherSearchInput(raws)
+		case "http_input":
+			return map[string]interface{}{
+				"http": map[string]interface{}{
+					"request": buildWatcherHTTPRequest(raws),
+				},
+			}
+		case "compare_condition":
+			return buildWatcherCompareCondition(raws)
+		case "script_condition":
+			return buildWatcherScriptCondition(raws)
+		case "action":
+			return buildWatcherActions(raws)
+		}
+	}
+
+	return nil
+}
+
+// equivalentWatcherJSON check the JSON read from the API and the object built from typed blocks are equivalent
+// Secrets redacted by the API are equal to any value
+func equivalentWatcherJSON(current string, value interface{}) bool {
+	var currentObj, newObj interface{}
+	if err := json.Unmarshal([]byte(current), &currentObj); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(convertInterfaceToJSONString(value)), &newObj); err != nil {
+		return false
+	}
+	currentObj = canonical.Normalize(normalizeWatcherJSON(currentObj), watcherJSONRules)
+	newObj = canonical.Normalize(normalizeWatcherJSON(newObj), watcherJSONRules)
+
+	return reflect.DeepEqual(replaceRedactedValues(currentObj, newObj), newObj)
+}
+
+// suppressEquivalentWatcherJSON permit to compare watch JSON in current state vs from API
+// It use the Elasticsearch defaults and value forms, and a redacted secret is equal to any value
+func suppressEquivalentWatcherJSON(k, old, new string, d *schema.ResourceData) bool {
+	var newObj interface{}
+	if err := json.Unmarshal([]byte(new), &newObj); err != nil {
+		return false
+	}
+
+	return equivalentWatcherJSON(old, newObj)
+}
+
+// normalizeWatcherJSON convert values in the form returned by the API:
+// durations in milliseconds, cron and email addresses as list and daily times as hour and minute objects
+func normalizeWatcherJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			switch key {
+			case "throttle_period", "timeout":
+				if s, ok := child.(string); ok {
+					if duration, err := parseElasticsearchDuration(s); err == nil {
+						result[key+"_in_millis"] = float64(duration.Milliseconds())
+						continue
+					}
+				}
+			case "cron":
+				if s, ok := child.(string); ok {
+					result[key] = []interface{}{s}
+					continue
+				}
+			case "at":
+				if times, ok := child.([]interface{}); ok {
+					result[key] = normalizeWatcherDailyTimes(times)
+					continue
+				}
+			case "email":
+				if email, ok := child.(map[string]interface{}); ok {
+					result[key] = normalizeWatcherJSON(normalizeWatcherEmailAddresses(email))
+					continue
+				}
+			}
+			result[key] = normalizeWatcherJSON(child)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, child := range v {
+			result = append(result, normalizeWatcherJSON(child))
+		}
+		return result
+	}
+
+	return value
+}
+
+// normalizeWatcherEmailAddresses convert email addresses provided as string to list
+func normalizeWatcherEmailAddresses(email map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(email))
+	for key, value := range email {
+		if address, ok := value.(string); ok && stringInSlice(key, []string{"to", "cc", "bcc", "reply_to"}) {
+			result[key] = []interface{}{address}
+			continue
+		}
+		result[key] = value
+	}
+
+	return result
+}
+
+// normalizeWatcherDailyTimes convert daily times like 17:00, noon or midnight to hour and minute objects
+func normalizeWatcherDailyTimes(times []interface{}) []interface{} {
+	result := make([]interface{}, 0, len(times))
+	for _, raw := range times {
+		s, ok := raw.(string)
+		if !ok {
+			result = append(result, raw)
+			continue
+		}
+
+		switch s {
+		case "noon":
+			s = "12:00"
+		case "midnight":
+			s = "00:00"
+		}
+		parts := strings.Split(s, ":")
+		if len(parts) != 2 {
+			result = append(result, raw)
+			continue
+		}
+		hour, err := strconv.Atoi(parts[0])
+		if err != nil {
+			result = append(result, raw)
+			continue
+		}
+		minute, err := strconv.Atoi(parts[1])
+		if err != nil {
+			result = append(result, raw)
+			continue
+		}
+
+		result = append(result, map[string]interface{}{
+			"hour":   []interface{}{float64(hour)},
+			"minute": []interface{}{float64(minute)},
+		})
+	}
+
+	return result
+}
+
+// buildWatcherSchedule convert schedule block to trigger object
+func buildWatcherSchedule(raws []interface{}) map[string]interface{} {
+	m := raws[0].(map[string]interface{})
+	schedule := make(map[string]interface{})
+
+	if interval := m["interval"].(string); interval != "" {
+		schedule["interval"] = interval
+	}
+	if crons := convertArrayInterfaceToArrayString(m["cron"].([]interface{})); len(crons) > 0 {
+		schedule["cron"] = crons
+	}
+	if daily := m["daily"].([]interface{}); len(daily) > 0 && daily[0] != nil {
+		schedule["daily"] = map[string]interface{}{
+			"at": convertArrayInterfaceToArrayString(daily[0].(map[string]interface{})["at"].([]interface{})),
+		}
+	}
+	if hourly := m["hourly"].([]interface{}); len(hourly) > 0 && hourly[0] != nil {
+		schedule["hourly"] = map[string]interface{}{
+			"minute": hourly[0].(map[string]interface{})["minute"],
+		}
+	}
+
+	return map[string]interface{}{
+		"schedule": schedule,
+	}
+}
+
+// buildWatcherSearchInput convert search_input block to input object
+func buildWatcherSearchInput(raws []interface{}) map[string]interface{} {
+	m := raws[0].(map[string]interface{})
+	request := map[string]interface{}{
+		"indices": convertArrayInterfaceToArrayString(m["indices"].([]interface{})),
+	}
+	if body := optionalInterfaceJSON(m["body"].(string)); body != nil {
+		request["body"] = body
+	}
+	if searchType := m["search_type"].(string); searchType != "" {
+		request["search_type"] = searchType
+	}
+
+	search := map[string]interface{}{
+		"request": request,
+	}
+	if timeout := m["timeout"].(string); timeout != "" {
+		search["timeout"] = timeout
+	}
+
+	return map[string]interface{}{
+		"search": search,
+	}
+}
+
+// buildWatcherHTTPRequest convert http request block to request object
+func buildWatcherHTTPRequest(raws []interface{}) map[string]interface{} {
+	m := raws[0].(map[string]interface{})
+	request := map[string]interface{}{
+		"scheme": m["scheme"].(string),
+		"host":   m["host"].(string),
+		"port":   m["port"].(int),
+		"method": m["method"].(string),
+	}
+	if path := m["path"].(string); path != "" {
+		request["path"] = path
+	}
+	if params := m["params"].(map[string]interface{}); len(params) > 0 {
+		request["params"] = params
+	}
+	if headers := m["headers"].(map[string]interface{}); len(headers) > 0 {
+		request["headers"] = headers
+	}
+	if body := m["body"].(string); body != "" {
+		request["body"] = body
+	}
+	if basicAuth := m["basic_auth"].([]interface{}); len(basicAuth) > 0 && basicAuth[0] != nil {
+		a := basicAuth[0].(map[string]interface{})
+		request["auth"] = map[string]interface{}{
+			"basic": map[string]interface{}{
+				"username": a["username"].(string),
+				"password": a["password"].(string),
+			},
+		}
+	}
+
+	return request
+}
+
+// buildWatcherCompareCondition convert compare_condition block to condition object
+func buildWatcherCompareCondition(raws []interface{}) map[string]interface{} {
+	m := raws[0].(map[string]interface{})
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(m["value"].(string)), &value); err != nil {
+		value = m["value"].(string)
+	}
+
+	return map[string]interface{}{
+		"compare": map[string]interface{}{
+			m["path"].(string): map[string]interface{}{
+				m["operator"].(string): value,
+			},
+		},
+	}
+}
+
+// buildWatcherScriptCondition convert script_condition block to condition object
+func buildWatcherScriptCondition(raws []interface{}) map[string]interface{} {
+	m := raws[0].(map[string]interface{})
+	script := map[string]interface{}{
+		"source": m["source"].(string),
+	}
+	if lang := m["lang"].(string); lang != "" {
+		script["lang"] = lang
+	}
+	if params := optionalInterfaceJSON(m["params"].(string)); params != nil {
+		script["params"] = params
+	}
+
+	return map[string]interface{}{
+		"script": script,
+	}
+}
+
+// buildWatcherActions convert action blocks to actions object
+func buildWatcherActions(raws []interface{}) map[string]interface{} {
+	actions := make(map[string]interface{})
+
+	for _, raw := range raws {
+		m := raw.(map[string]interface{})
+		action := make(map[string]interface{})
+
+		if throttlePeriod := m["throttle_period"].(string); throttlePeriod != "" {
+			action["throttle_period"] = throttlePeriod
+		}
+		if condition := optionalInterfaceJSON(m["condition"].(string)); condition != nil {
+			action["condition"] = condition
+		}
+
+		if l := m["email"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			email := map[string]interface{}{
+				"to": convertArrayInterfaceToArrayString(a["to"].([]interface{})),
+			}
+			if subject := a["subject"].(string); subject != "" {
+				email["subject"] = subject
+			}
+			if body := a["body"].(string); body != "" {
+				email["body"] = map[string]interface{}{
+					"text": body,
+				}
+			}
+			action["email"] = email
+		}
+		if l := m["webhook"].([]interface{}); len(l) > 0 && l[0] != nil {
+			action["webhook"] = buildWatcherHTTPRequest(l)
+		}
+		if l := m["slack"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			slack := map[string]interface{}{
+				"message": map[string]interface{}{
+					"to":   convertArrayInterfaceToArrayString(a["to"].([]interface{})),
+					"text": a["text"].(string),
+				},
+			}
+			if account := a["account"].(string); account != "" {
+				slack["account"] = account
+			}
+			action["slack"] = slack
+		}
+		if l := m["index"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			index := map[string]interface{}{
+				"index": a["index"].(string),
+			}
+			if docID := a["doc_id"].(string); docID != "" {
+				index["doc_id"] = docID
+			}
+			action["index"] = index
+		}
+		if l := m["logging"].([]interface{}); len(l) > 0 && l[0] != nil {
+			a := l[0].(map[string]interface{})
+			logging := map[string]interface{}{
+				"text": a["text"].(string),
+			}
+			if level := a["level"].(string); level != "" {
+				logging["level"] = level
+			}
+			if category := a["category"].(string); category != "" {
+				logging["category"] = category
+			}
+			action["logging"] = logging
+		}
+
+		actions[m["name"].(string)] = action
+	}
+
+	return actions
+}
+
+// mergeWatcherSecrets set secret values in actions object
+// Secret keys are dotted paths from the actions object root
+func mergeWatcherSecrets(actions interface{}, secrets map[string]string) (interface{}, error) {
+	b, err := json.Marshal(actions)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]interface{})
+	if actions != nil {
+		if err = json.Unmarshal(b, &merged); err != nil {
+			return nil, err
+		}
+	}
+
+	for path, value := range secrets {
+		keys := strings.Split(path, ".")
+		current := merged
+		for _, key := range keys[:len(keys)-1] {
+			if _, ok := current[key]; !ok {
+				current[key] = make(map[string]interface{})
+			}
+			next, ok := current[key].(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("Secret %s can't be merged in actions, %s is not an object", path, key)
+			}
+			current = next
+		}
+		current[keys[len(keys)-1]] = value
+	}
+
+	return merged, nil
+}
@@ -2,10 +2,13 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
-	elastic "github.com/elastic/go-elasticsearch/v7"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/terraform"
 	"github.com/pkg/errors"
@@ -30,18 +33,310 @@ func TestAccElasticsearchWatcher(t *testing.T) {
 				Config: testElasticsearchWatcherUpdate,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckElasticsearchWatcherExists("elasticsearch_watcher.test"),
+					resource.TestCheckResourceAttr("elasticsearch_watcher.test", "active", "false"),
 				),
 			},
 			{
-				ResourceName:            "elasticsearch_watcher.test",
-				ImportState:             true,
-				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"trigger", "input", "condition", "actions", "metadata"},
+				ResourceName:      "elasticsearch_watcher.test",
+				ImportState:       true,
+				ImportStateVerify: true,
 			},
 		},
 	})
 }
 
+func TestAccElasticsearchWatcherTestExecutionFailed(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchWatcherDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchWatcher,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchWatcherExists("elasticsearch_watcher.test"),
+				),
+			},
+			{
+				Config:      testElasticsearchWatcherTestExecutionFailed,
+				ExpectError: regexp.MustCompile("Test execution of watcher terraform-test failed"),
+			},
+			{
+				Config:   testElasticsearchWatcher,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchWatcherTyped(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchWatcherDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchWatcherTyped,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchWatcherExists("elasticsearch_watcher.test"),
+					resource.TestCheckResourceAttrSet("elasticsearch_watcher.test", "trigger"),
+					resource.TestCheckResourceAttrSet("elasticsearch_watcher.test", "actions"),
+				),
+			},
+			{
+				Config:   testElasticsearchWatcherTyped,
+				PlanOnly: true,
+			},
+			{
+				PreConfig:          testPutElasticsearchWatcher(t, "terraform-test", `{"trigger": {"schedule": {"interval": "1h"}}, "input": {"none": {}}, "actions": {}}`),
+				Config:             testElasticsearchWatcherTyped,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchWatcherSecrets(t *testing.T) {
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchWatcherDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testElasticsearchWatcherSecrets,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchWatcherExists("elasticsearch_watcher.test"),
+				),
+			},
+			{
+				Config:             testElasticsearchWatcherSecrets,
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: false,
+			},
+		},
+	})
+}
+
+func TestCheckWatcherRedactedValues(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   interface{}
+		actions interface{}
+		secrets map[string]string
+		isError bool
+	}{
+		{
+			name:    "secrets provided",
+			input:   json.RawMessage(`{"http": {"request": {"host": "localhost", "port": 80}}}`),
+			actions: map[string]interface{}{"a": map[string]interface{}{"webhook": map[string]interface{}{"auth": map[string]interface{}{"basic": map[string]interface{}{"password": "secret"}}}}},
+			isError: false,
+		},
+		{
+			name:    "redacted action filled by secrets",
+			actions: json.RawMessage(`{"a": {"webhook": {"auth": {"basic": {"username": "user", "password": "::es_redacted::"}}}}}`),
+			secrets: map[string]string{"a.webhook.auth.basic.password": "secret"},
+			isError: false,
+		},
+		{
+			name:    "redacted action not filled by secrets",
+			actions: json.RawMessage(`{"a": {"webhook": {"auth": {"basic": {"password": "::es_redacted::"}}}}, "b": {"webhook": {"auth": {"basic": {"password": "::es_redacted::"}}}}}`),
+			secrets: map[string]string{"a.webhook.auth.basic.password": "secret"},
+			isError: true,
+		},
+		{
+			name:    "redacted input",
+			input:   json.RawMessage(`{"http": {"request": {"headers": {"Authorization": "::es_redacted::"}}}}`),
+			secrets: map[string]string{"http.request.headers.Authorization": "secret"},
+			isError: true,
+		},
+		{
+			name:    "redacted action in list",
+			actions: json.RawMessage(`{"a": {"email": {"attachments": [{"password": "::es_redacted::"}]}}}`),
+			isError: true,
+		},
+	}
+
+	for _, c := range cases {
+		if err := checkWatcherRedactedValues("test", c.input, c.actions, c.secrets); (err != nil) != c.isError {
+			t.Errorf("%s: expected error to be %t, got %v", c.name, c.isError, err)
+		}
+	}
+}
+
+func TestGetRedactedPaths(t *testing.T) {
+	actions := json.RawMessage(`{"b": {"email": {"attachments": [{"password": "::es_redacted::"}]}}, "a": {"webhook": {"auth": {"basic": {"password": "::es_redacted::"}}}}}`)
+	expected := []string{"a.webhook.auth.basic.password", "b.email.attachments.0.password"}
+
+	if actual := getRedactedPaths(actions); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestSuppressEquivalentWatcherJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		suppress bool
+	}{
+		{
+			name:     "search input without defaults",
+			old:      `{"search": {"request": {"search_type": "query_then_fetch", "indices": ["logstash*"], "rest_total_hits_as_int": true, "body": {"query": {"match_all": {}}}}}}`,
+			new:      `{"search": {"request": {"indices": ["logstash*"], "body": {"query": {"match_all": {}}}}}}`,
+			suppress: true,
+		},
+		{
+			name:     "email address as string",
+			old:      `{"email_admin": {"email": {"profile": "standard", "to": ["admin@domain.host.com"], "subject": "404 recently encountered"}}}`,
+			new:      `{"email_admin": {"email": {"profile": "standard", "to": "admin@domain.host.com", "subject": "404 recently encountered"}}}`,
+			suppress: true,
+		},
+		{
+			name:     "cron as string",
+			old:      `{"schedule": {"cron": ["0 0/1 * * * ?"]}}`,
+			new:      `{"schedule": {"cron": "0 0/1 * * * ?"}}`,
+			suppress: true,
+		},
+		{
+			name:     "redacted secret",
+			old:      `{"hook": {"webhook": {"host": "localhost", "auth": {"basic": {"username": "user", "password": "::es_redacted::"}}}}}`,
+			new:      `{"hook": {"webhook": {"host": "localhost", "auth": {"basic": {"username": "user", "password": "secret"}}}}}`,
+			suppress: true,
+		},
+		{
+			name:     "different email address",
+			old:      `{"email_admin": {"email": {"to": ["admin@domain.host.com"]}}}`,
+			new:      `{"email_admin": {"email": {"to": "other@domain.host.com"}}}`,
+			suppress: false,
+		},
+		{
+			name:     "invalid JSON",
+			old:      `{"schedule": {"cron": ["0 0/1 * * * ?"]}}`,
+			new:      `{"schedule":`,
+			suppress: false,
+		},
+	}
+
+	for _, c := range cases {
+		if suppress := suppressEquivalentWatcherJSON("input", c.old, c.new, nil); suppress != c.suppress {
+			t.Errorf("%s: expected suppress to be %t", c.name, c.suppress)
+		}
+	}
+}
+
+func TestEquivalentWatcherJSON(t *testing.T) {
+	cases := []struct {
+		name       string
+		current    string
+		value      interface{}
+		equivalent bool
+	}{
+		{
+			name:    "daily schedule",
+			current: `{"schedule": {"daily": {"at": [{"hour": [17], "minute": [0]}, {"hour": [12], "minute": [30]}]}}}`,
+			value: map[string]interface{}{
+				"schedule": map[string]interface{}{
+					"daily": map[string]interface{}{
+						"at": []string{"17:00", "12:30"},
+					},
+				},
+			},
+			equivalent: true,
+		},
+		{
+			name:    "single cron",
+			current: `{"schedule": {"cron": "0 0 12 * * ?"}}`,
+			value: map[string]interface{}{
+				"schedule": map[string]interface{}{
+					"cron": []string{"0 0 12 * * ?"},
+				},
+			},
+			equivalent: true,
+		},
+		{
+			name:    "search input defaults",
+			current: `{"search": {"request": {"search_type": "query_then_fetch", "indices": ["logs"], "rest_total_hits_as_int": true, "body": {"size": 0}}, "timeout_in_millis": 30000}}`,
+			value: map[string]interface{}{
+				"search": map[string]interface{}{
+					"request": map[string]interface{}{
+						"indices": []string{"logs"},
+						"body":    json.RawMessage(`{"size": 0}`),
+					},
+					"timeout": "30s",
+				},
+			},
+			equivalent: true,
+		},
+		{
+			name:    "actions defaults and redacted secrets",
+			current: `{"log": {"throttle_period_in_millis": 900000, "logging": {"level": "info", "text": "error"}}, "hook": {"webhook": {"scheme": "http", "host": "localhost", "port": 80, "method": "post", "auth": {"basic": {"username": "user", "password": "::es_redacted::"}}}}}`,
+			value: map[string]interface{}{
+				"log": map[string]interface{}{
+					"throttle_period": "15m",
+					"logging": map[string]interface{}{
+						"text": "error",
+					},
+				},
+				"hook": map[string]interface{}{
+					"webhook": map[string]interface{}{
+						"scheme": "http",
+						"host":   "localhost",
+						"port":   80,
+						"method": "post",
+						"auth": map[string]interface{}{
+							"basic": map[string]interface{}{
+								"username": "user",
+								"password": "secret",
+							},
+						},
+					},
+				},
+			},
+			equivalent: true,
+		},
+		{
+			name:    "changed outside of terraform",
+			current: `{"schedule": {"interval": "1h"}}`,
+			value: map[string]interface{}{
+				"schedule": map[string]interface{}{
+					"interval": "10m",
+				},
+			},
+			equivalent: false,
+		},
+	}
+
+	for _, c := range cases {
+		if equivalent := equivalentWatcherJSON(c.current, c.value); equivalent != c.equivalent {
+			t.Errorf("%s: expected equivalent to be %t", c.name, c.equivalent)
+		}
+	}
+}
+
+func testPutElasticsearchWatcher(t *testing.T, name string, body string) func() {
+	return func() {
+		client := testAccProvider.Meta().(*providerConf).client
+		res, err := client.API.Watcher.PutWatch(name, client.API.Watcher.PutWatch.WithBody(strings.NewReader(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if res.IsError() {
+			t.Fatalf("Error when put watcher %s: %s", name, res.String())
+		}
+	}
+}
+
 func testCheckElasticsearchWatcherExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -54,7 +349,7 @@ func testCheckElasticsearchWatcherExists(name string) resource.TestCheckFunc {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Watcher.GetWatch(
 			rs.Primary.ID,
 			client.API.Watcher.GetWatch.WithContext(context.Background()),
@@ -80,7 +375,7 @@ func testCheckElasticsearchWatcherDestroy(s *terraform.State) error {
 
 		meta := testAccProvider.Meta()
 
-		client := meta.(*elastic.Client)
+		client := meta.(*providerConf).client
 		res, err := client.API.Watcher.GetWatch(
 			rs.Primary.ID,
 			client.API.Watcher.GetWatch.WithContext(context.Background()),
@@ -155,12 +450,19 @@ EOF
     }
 }
 EOF
+  test_execution {
+    action_modes = {
+      "email_admin" = "skip"
+    }
+  }
 }
 `
 
 var testElasticsearchWatcherUpdate = `
 resource "elasticsearch_watcher" "test" {
   name		= "terraform-test"
+  active	= false
+  throttle_period = "5m"
   trigger	= <<EOF
 {
 	"schedule" : { "cron" : "1 0/1 * * * ?" }
@@ -213,3 +515,106 @@ EOF
 EOF
 }
 `
+
+var testElasticsearchWatcherTyped = `
+resource "elasticsearch_watcher" "test" {
+  name = "terraform-test"
+
+  schedule {
+    interval = "10m"
+  }
+
+  search_input {
+    indices = ["logstash*"]
+    body    = <<EOF
+{
+  "query": {
+    "match": {
+      "response": 404
+    }
+  }
+}
+EOF
+  }
+
+  compare_condition {
+    path     = "ctx.payload.hits.total"
+    operator = "gt"
+    value    = "0"
+  }
+
+  action {
+    name            = "log_error"
+    throttle_period = "15m"
+    logging {
+      text  = "404 recently encountered"
+      level = "warn"
+    }
+  }
+
+  action {
+    name = "index_payload"
+    index {
+      index = "watcher-404"
+    }
+  }
+}
+`
+
+var testElasticsearchWatcherSecrets = `
+resource "elasticsearch_watcher" "test" {
+  name = "terraform-test"
+
+  schedule {
+    interval = "10m"
+  }
+
+  action {
+    name = "my_webhook"
+    webhook {
+      host   = "localhost"
+      port   = 8080
+      method = "post"
+      path   = "/alert"
+      body   = "404 recently encountered"
+      basic_auth {
+        username = "user"
+        password = "changeme"
+      }
+    }
+  }
+
+  secrets = {
+    "my_webhook.webhook.headers.Authorization" = "Bearer changeme"
+  }
+}
+`
+
+var testElasticsearchWatcherTestExecutionFailed = `
+resource "elasticsearch_watcher" "test" {
+  name = "terraform-test"
+
+  schedule {
+    cron = ["0 0/1 * * * ?"]
+  }
+
+  input = jsonencode({
+    simple = {
+      message = "test"
+    }
+  })
+
+  script_condition {
+    source = "throw new IllegalStateException('test')"
+  }
+
+  action {
+    name = "log"
+    logging {
+      text = "test"
+    }
+  }
+
+  test_execution {}
+}
+`
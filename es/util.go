@@ -1,6 +1,18 @@
 package es
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/ggsood/terraform-provider-elasticsearch/v7/es/canonical"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
 
 // optionalInterfaceJSON permit to convert string as json object
 func optionalInterfaceJSON(input string) interface{} {
@@ -11,6 +23,19 @@ func optionalInterfaceJSON(input string) interface{} {
 
 }
 
+// convertInterfaceToJSONString permit to convert object as json string
+func convertInterfaceToJSONString(raw interface{}) string {
+	if raw == nil {
+		return ""
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
 // convertArrayInterfaceToArrayString permit to convert an array of interface to an array of string
 func convertArrayInterfaceToArrayString(raws []interface{}) []string {
 	data := make([]string, len(raws))
@@ -29,3 +54,320 @@ func convertMapInterfaceToMapString(raws map[string]interface{}) map[string]stri
 
 	return data
 }
+
+// stringInSlice permit to check if string is in slice of string
+func stringInSlice(value string, list []string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseElasticsearchDuration permit to convert Elasticsearch time unit like 30d in duration
+func parseElasticsearchDuration(value string) (time.Duration, error) {
+	matches := durationRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, errors.Errorf("%s is not an Elasticsearch duration", value)
+	}
+	number, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	units := map[string]time.Duration{
+		"d":      24 * time.Hour,
+		"h":      time.Hour,
+		"m":      time.Minute,
+		"s":      time.Second,
+		"ms":     time.Millisecond,
+		"micros": time.Microsecond,
+		"nanos":  time.Nanosecond,
+	}
+
+	return time.Duration(number) * units[matches[2]], nil
+}
+
+// blockTypes return the list of keys that are set on the block
+func blockTypes(block map[string]interface{}, keys ...string) []string {
+	types := make([]string, 0)
+	for _, key := range keys {
+		switch v := block[key].(type) {
+		case string:
+			if v != "" {
+				types = append(types, key)
+			}
+		case []interface{}:
+			if len(v) > 0 {
+				types = append(types, key)
+			}
+		}
+	}
+
+	return types
+}
+
+// indexPatternsOverlap check if there are index names matching both patterns
+// Patterns only support the * wildcard like Elasticsearch index patterns
+func indexPatternsOverlap(a, b string) bool {
+	memo := make(map[[2]int]bool)
+	var overlap func(i, j int) bool
+	overlap = func(i, j int) bool {
+		key := [2]int{i, j}
+		if result, ok := memo[key]; ok {
+			return result
+		}
+
+		var result bool
+		switch {
+		case i == len(a) && j == len(b):
+			result = true
+		case i < len(a) && a[i] == '*':
+			result = overlap(i+1, j) || (j < len(b) && overlap(i, j+1))
+		case j < len(b) && b[j] == '*':
+			result = overlap(i, j+1) || (i < len(a) && overlap(i+1, j))
+		case i < len(a) && j < len(b):
+			result = a[i] == b[j] && overlap(i+1, j+1)
+		}
+
+		memo[key] = result
+		return result
+	}
+
+	return overlap(0, 0)
+}
+
+// isEmptyJSONObject check if decoded JSON value is nil or an empty object
+func isEmptyJSONObject(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	m, ok := value.(map[string]interface{})
+	return ok && len(m) == 0
+}
+
+// canonicalJSONStateFunc store JSON string attribute in its canonical form
+// Invalid JSON is stored as is, the validation is done by ValidateFunc
+func canonicalJSONStateFunc(rules *canonical.Rules) schema.SchemaStateFunc {
+	return func(v interface{}) string {
+		result, err := canonical.Canonicalize(v.(string), rules)
+		if err != nil {
+			return v.(string)
+		}
+
+		return result
+	}
+}
+
+// managedByKey is the key of ownership marker stamped in object metadata
+const managedByKey = "managed_by"
+
+// managedBySchema return the schema of the ownership marker stamped in object metadata
+func managedBySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Optional:    true,
+		Description: "The ownership marker stamped in object metadata, like workspace and module",
+		Elem: &schema.Schema{
+			Type: schema.TypeString,
+		},
+	}
+}
+
+// takeoverSchema return the schema of attribute that permit to overwrite object owned by other marker
+func takeoverSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Overwrite the object even if it's managed by an other ownership marker, or if it already exist when the provider is in create only mode",
+	}
+}
+
+// stampManagedBy set the ownership marker in the object found at path of JSON body
+// The body is returned unchanged if marker is empty
+func stampManagedBy(body string, path []string, marker map[string]interface{}) (string, error) {
+	if len(marker) == 0 {
+		return body, nil
+	}
+
+	var value interface{}
+	if body != "" {
+		decoder := json.NewDecoder(strings.NewReader(body))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return "", err
+		}
+	}
+
+	root, ok := value.(map[string]interface{})
+	if !ok {
+		root = make(map[string]interface{})
+	}
+	current := root
+	for _, key := range path {
+		child, ok := current[key].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			current[key] = child
+		}
+		current = child
+	}
+	current[managedByKey] = marker
+
+	b, err := json.Marshal(root)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// getManagedBy return the ownership marker in the object found at path of decoded JSON value
+func getManagedBy(value interface{}, path []string) map[string]interface{} {
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	marker, _ := m[managedByKey].(map[string]interface{})
+
+	return marker
+}
+
+// removeManagedBy return the metadata object without the ownership marker
+// It return nil if there are nothing else in metadata
+func removeManagedBy(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+
+	result := make(map[string]interface{})
+	for k, v := range m {
+		if k != managedByKey {
+			result[k] = v
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+// checkManagedBy check the object is not owned by an other ownership marker
+// Objects without marker can be adopted. The marker can be the one from state when it change in configuration.
+func checkManagedBy(objectType string, name string, current map[string]interface{}, d *schema.ResourceData) error {
+	if len(current) == 0 || d.Get("takeover").(bool) {
+		return nil
+	}
+
+	oldMarker, newMarker := d.GetChange("managed_by")
+	if reflect.DeepEqual(current, oldMarker) || reflect.DeepEqual(current, newMarker) {
+		return nil
+	}
+
+	return errors.Errorf("%s %s is managed by %s, set takeover to true to overwrite it", objectType, name, convertInterfaceToJSONString(current))
+}
+
+// importStateManagedBy return an importer that keep the ownership marker of imported object
+// getManagedBy return the marker of object. The marker is read in state, so the next plan show it when
+// it differ from the configuration, and the owner can import again its objects after a state loss
+func importStateManagedBy(objectType string, getManagedBy func(id string, meta interface{}) (map[string]interface{}, error)) schema.StateFunc {
+	return func(d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+		marker, err := getManagedBy(d.Id(), meta)
+		if err != nil {
+			return nil, err
+		}
+		if len(marker) > 0 {
+			log.Warnf("%s %s is managed by %s, check it's the managed_by of your configuration", objectType, d.Id(), convertInterfaceToJSONString(marker))
+			d.Set("managed_by", marker)
+		}
+
+		return []*schema.ResourceData{d}, nil
+	}
+}
+
+// checkCreateOnly return an error if the provider is in create only mode and the object already exist
+// get is the get API call of the object
+func checkCreateOnly(objectType string, name string, meta interface{}, get func() (*esapi.Response, error)) error {
+	if !meta.(*providerConf).createOnly {
+		return nil
+	}
+
+	res, err := get()
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == 404 {
+		return nil
+	}
+	if res.IsError() {
+		return errors.Errorf("Error when check if %s %s already exist: %s", objectType, name, res.String())
+	}
+
+	return alreadyExistError(objectType, name)
+}
+
+// isCreateOnly check if the object is being created while the provider is in create only mode
+// The ID is only set once the object is created. Checks are done in this order: takeover bypass create_only,
+// then create_only refuse existing objects, then the ownership marker is checked
+func isCreateOnly(d *schema.ResourceData, meta interface{}) bool {
+	if takeover, ok := d.GetOk("takeover"); ok && takeover.(bool) {
+		return false
+	}
+
+	return d.Id() == "" && meta.(*providerConf).createOnly
+}
+
+// alreadyExistError return the error when create object that already exist in create only mode
+func alreadyExistError(objectType string, name string) error {
+	return errors.Errorf("%s %s already exist, import it to manage it with terraform or disable create_only on provider", objectType, name)
+}
+
+// isAlreadyExistResponse check if the API refused to create object because it already exist
+func isAlreadyExistResponse(res *esapi.Response) bool {
+	return res.StatusCode == 400 && strings.Contains(res.String(), "already exists")
+}
+
+// deletionProtectionSchema return the schema of attribute that prevent object to be deleted
+func deletionProtectionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeBool,
+		Optional:    true,
+		Default:     false,
+		Description: "Prevent the object to be deleted, it must be set to false and applied before delete it",
+	}
+}
+
+// onlyDeletionProtectionChanged return true if deletion_protection is the only attribute changed
+// It permit to not call the API again when the object is not changed
+func onlyDeletionProtectionChanged(d *schema.ResourceData, resourceSchema map[string]*schema.Schema) bool {
+	for key := range resourceSchema {
+		if key != "deletion_protection" && d.HasChange(key) {
+			return false
+		}
+	}
+
+	return d.HasChange("deletion_protection")
+}
+
+// checkDeletionProtection return an error if the object is protected against deletion
+func checkDeletionProtection(objectType string, name string, d *schema.ResourceData) error {
+	if d.Get("deletion_protection").(bool) {
+		return errors.Errorf("%s %s is protected against deletion, set deletion_protection to false and apply before delete it", objectType, name)
+	}
+
+	return nil
+}
@@ -0,0 +1,348 @@
+package es
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/terraform"
+)
+
+func TestIndexPatternsOverlap(t *testing.T) {
+	cases := []struct {
+		a       string
+		b       string
+		overlap bool
+	}{
+		{"logs-*", "logs-*", true},
+		{"logs-*", "logs-app-*", true},
+		{"logs-*", "*-app", true},
+		{"logs-*", "logs-app", true},
+		{"*", "metrics", true},
+		{"logs-*", "metrics-*", false},
+		{"logs-*-prod", "logs-*-dev", false},
+		{"logs", "logs-app", false},
+	}
+
+	for _, c := range cases {
+		if overlap := indexPatternsOverlap(c.a, c.b); overlap != c.overlap {
+			t.Errorf("%q and %q: expected overlap to be %t", c.a, c.b, c.overlap)
+		}
+		if overlap := indexPatternsOverlap(c.b, c.a); overlap != c.overlap {
+			t.Errorf("%q and %q: expected overlap to be %t", c.b, c.a, c.overlap)
+		}
+	}
+}
+
+func TestStampManagedBy(t *testing.T) {
+	marker := map[string]interface{}{
+		"workspace": "prod",
+		"module":    "logs",
+	}
+
+	cases := []struct {
+		name     string
+		body     string
+		path     []string
+		marker   map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "empty marker",
+			body:     `{"description": "test", "version": 12345678901234567890}`,
+			path:     []string{"_meta"},
+			marker:   map[string]interface{}{},
+			expected: `{"description": "test", "version": 12345678901234567890}`,
+		},
+		{
+			name:     "nested path",
+			body:     `{"policy": {"phases": {}}}`,
+			path:     []string{"policy", "_meta"},
+			marker:   marker,
+			expected: `{"policy":{"_meta":{"managed_by":{"module":"logs","workspace":"prod"}},"phases":{}}}`,
+		},
+		{
+			name:     "keep existing metadata and numbers",
+			body:     `{"_meta": {"team": "search"}, "version": 12345678901234567890}`,
+			path:     []string{"_meta"},
+			marker:   marker,
+			expected: `{"_meta":{"managed_by":{"module":"logs","workspace":"prod"},"team":"search"},"version":12345678901234567890}`,
+		},
+		{
+			name:     "empty body",
+			body:     "",
+			path:     nil,
+			marker:   marker,
+			expected: `{"managed_by":{"module":"logs","workspace":"prod"}}`,
+		},
+	}
+
+	for _, c := range cases {
+		actual, err := stampManagedBy(c.body, c.path, c.marker)
+		if err != nil {
+			t.Fatalf("%s: %s", c.name, err)
+		}
+		if actual != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestGetManagedBy(t *testing.T) {
+	marker := map[string]interface{}{
+		"workspace": "prod",
+	}
+	value := map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"_meta": map[string]interface{}{
+				"managed_by": marker,
+			},
+		},
+	}
+
+	if actual := getManagedBy(value, []string{"mappings", "_meta"}); !reflect.DeepEqual(marker, actual) {
+		t.Errorf("Expected %+v, got %+v", marker, actual)
+	}
+	if actual := getManagedBy(value, []string{"_meta"}); actual != nil {
+		t.Errorf("Expected no marker, got %+v", actual)
+	}
+	if actual := removeManagedBy(value["mappings"].(map[string]interface{})["_meta"]); actual != nil {
+		t.Errorf("Expected empty metadata to be removed, got %+v", actual)
+	}
+}
+
+func TestCheckManagedBy(t *testing.T) {
+	resourceSchema := map[string]*schema.Schema{
+		"managed_by": managedBySchema(),
+		"takeover":   takeoverSchema(),
+	}
+
+	cases := []struct {
+		name    string
+		state   map[string]string
+		config  map[string]interface{}
+		current map[string]interface{}
+		isError bool
+	}{
+		{
+			name:    "object without marker",
+			config:  map[string]interface{}{"managed_by": map[string]interface{}{"workspace": "team-a"}},
+			current: nil,
+			isError: false,
+		},
+		{
+			name:    "same marker",
+			config:  map[string]interface{}{"managed_by": map[string]interface{}{"workspace": "team-a"}},
+			current: map[string]interface{}{"workspace": "team-a"},
+			isError: false,
+		},
+		{
+			name:    "foreign marker",
+			config:  map[string]interface{}{"managed_by": map[string]interface{}{"workspace": "team-a"}},
+			current: map[string]interface{}{"workspace": "team-b"},
+			isError: true,
+		},
+		{
+			name:    "foreign marker with takeover",
+			config:  map[string]interface{}{"managed_by": map[string]interface{}{"workspace": "team-a"}, "takeover": true},
+			current: map[string]interface{}{"workspace": "team-b"},
+			isError: false,
+		},
+		{
+			name:    "marker changed in configuration",
+			state:   map[string]string{"managed_by.%": "1", "managed_by.workspace": "team-a", "takeover": "false"},
+			config:  map[string]interface{}{"managed_by": map[string]interface{}{"workspace": "team-b"}},
+			current: map[string]interface{}{"workspace": "team-a"},
+			isError: false,
+		},
+		{
+			name:    "marker removed from configuration",
+			state:   map[string]string{"managed_by.%": "1", "managed_by.workspace": "team-a", "takeover": "false"},
+			config:  map[string]interface{}{},
+			current: map[string]interface{}{"workspace": "team-a"},
+			isError: false,
+		},
+		{
+			name:    "foreign marker on update",
+			state:   map[string]string{"managed_by.%": "1", "managed_by.workspace": "team-a", "takeover": "false"},
+			config:  map[string]interface{}{"managed_by": map[string]interface{}{"workspace": "team-a"}},
+			current: map[string]interface{}{"workspace": "team-c"},
+			isError: true,
+		},
+	}
+
+	for _, c := range cases {
+		var d *schema.ResourceData
+		if c.state == nil {
+			d = schema.TestResourceDataRaw(t, resourceSchema, c.config)
+		} else {
+			state := &terraform.InstanceState{ID: "test", Attributes: c.state}
+			diff, err := schema.InternalMap(resourceSchema).Diff(state, terraform.NewResourceConfigRaw(c.config), nil, nil, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d, err = schema.InternalMap(resourceSchema).Data(state, diff)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := checkManagedBy("Test", "test", c.current, d); (err != nil) != c.isError {
+			t.Errorf("%s: expected error to be %t, got %v", c.name, c.isError, err)
+		}
+	}
+}
+
+func TestImportStateManagedBy(t *testing.T) {
+	markers := map[string]map[string]interface{}{
+		"owned": {"workspace": "team-a"},
+	}
+	importer := importStateManagedBy("Test", func(id string, meta interface{}) (map[string]interface{}, error) {
+		return markers[id], nil
+	})
+	resourceSchema := map[string]*schema.Schema{
+		"managed_by": managedBySchema(),
+	}
+
+	for id, expected := range map[string]map[string]interface{}{"owned": {"workspace": "team-a"}, "free": {}} {
+		d := schema.TestResourceDataRaw(t, resourceSchema, map[string]interface{}{})
+		d.SetId(id)
+		results, err := importer(d, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", id, err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("%s: expected 1 imported object, got %d", id, len(results))
+		}
+		if actual := results[0].Get("managed_by").(map[string]interface{}); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected managed_by %v, got %v", id, expected, actual)
+		}
+	}
+}
+
+func TestIsAlreadyExistResponse(t *testing.T) {
+	cases := []struct {
+		name       string
+		statusCode int
+		body       string
+		expected   bool
+	}{
+		{
+			name:       "legacy template",
+			statusCode: 400,
+			body:       `{"error":{"type":"illegal_argument_exception","reason":"index_template [terraform-test] already exists"},"status":400}`,
+			expected:   true,
+		},
+		{
+			name:       "composable template",
+			statusCode: 400,
+			body:       `{"error":{"type":"illegal_argument_exception","reason":"index template [terraform-test] already exists"},"status":400}`,
+			expected:   true,
+		},
+		{
+			name:       "other bad request",
+			statusCode: 400,
+			body:       `{"error":{"type":"illegal_argument_exception","reason":"unknown setting [index.foo]"},"status":400}`,
+			expected:   false,
+		},
+		{
+			name:       "server error",
+			statusCode: 500,
+			body:       `{"error":{"type":"exception","reason":"already exists"},"status":500}`,
+			expected:   false,
+		},
+	}
+
+	for _, c := range cases {
+		res := &esapi.Response{
+			StatusCode: c.statusCode,
+			Body:       ioutil.NopCloser(strings.NewReader(c.body)),
+		}
+		if actual := isAlreadyExistResponse(res); actual != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestOnlyDeletionProtectionChanged(t *testing.T) {
+	resourceSchema := map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			Required: true,
+		},
+		"deletion_protection": deletionProtectionSchema(),
+	}
+
+	cases := []struct {
+		name     string
+		config   map[string]interface{}
+		expected bool
+	}{
+		{
+			name:     "nothing changed",
+			config:   map[string]interface{}{"name": "test"},
+			expected: false,
+		},
+		{
+			name:     "only deletion protection changed",
+			config:   map[string]interface{}{"name": "test", "deletion_protection": true},
+			expected: true,
+		},
+		{
+			name:     "other attribute changed",
+			config:   map[string]interface{}{"name": "test2"},
+			expected: false,
+		},
+		{
+			name:     "deletion protection and other attribute changed",
+			config:   map[string]interface{}{"name": "test2", "deletion_protection": true},
+			expected: false,
+		},
+	}
+
+	for _, c := range cases {
+		state := &terraform.InstanceState{ID: "test", Attributes: map[string]string{"name": "test", "deletion_protection": "false"}}
+		diff, err := schema.InternalMap(resourceSchema).Diff(state, terraform.NewResourceConfigRaw(c.config), nil, nil, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := schema.InternalMap(resourceSchema).Data(state, diff)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual := onlyDeletionProtectionChanged(d, resourceSchema); actual != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.name, c.expected, actual)
+		}
+	}
+}
+
+func TestIsCreateOnly(t *testing.T) {
+	resourceSchema := map[string]*schema.Schema{
+		"takeover": takeoverSchema(),
+	}
+
+	cases := []struct {
+		name       string
+		id         string
+		takeover   bool
+		createOnly bool
+		expected   bool
+	}{
+		{"create in create only mode", "", false, true, true},
+		{"create with takeover in create only mode", "", true, true, false},
+		{"create without create only mode", "", false, false, false},
+		{"update in create only mode", "test", false, true, false},
+	}
+
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceSchema, map[string]interface{}{"takeover": c.takeover})
+		d.SetId(c.id)
+		if actual := isCreateOnly(d, &providerConf{createOnly: c.createOnly}); actual != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.name, c.expected, actual)
+		}
+	}
+}
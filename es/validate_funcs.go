@@ -0,0 +1,83 @@
+package es
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	durationRegexp  = regexp.MustCompile(`^([0-9]+)(d|h|m|s|ms|micros|nanos)$`)
+	cronFieldRegexp = regexp.MustCompile(`^[0-9A-Za-z*?,/#\-]+$`)
+)
+
+// validateElasticsearchDuration permit to check the value is an Elasticsearch time unit like 30d or 12h
+func validateElasticsearchDuration(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+		return warnings, errors
+	}
+
+	if !durationRegexp.MatchString(v) {
+		errors = append(errors, fmt.Errorf("expected %s to be an Elasticsearch duration (like 30d, 12h, 5m), got %s", k, v))
+	}
+
+	return warnings, errors
+}
+
+// validateElasticsearchCron permit to check the value is an Elasticsearch cron expression
+// <seconds> <minutes> <hours> <day_of_month> <month> <day_of_week> [year]
+func validateElasticsearchCron(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %s to be string", k))
+		return warnings, errors
+	}
+
+	fields := strings.Fields(v)
+	if len(fields) < 6 || len(fields) > 7 {
+		errors = append(errors, fmt.Errorf("expected %s to be a cron expression with 6 or 7 fields, got %q", k, v))
+		return warnings, errors
+	}
+	for _, field := range fields {
+		if !cronFieldRegexp.MatchString(field) {
+			errors = append(errors, fmt.Errorf("expected %s to be a cron expression, field %q is invalid", k, field))
+		}
+	}
+
+	return warnings, errors
+}
+
+// validateWatcherActionModes permit to check the action modes used to execute a watch
+func validateWatcherActionModes(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(map[string]interface{})
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %s to be map", k))
+		return warnings, errors
+	}
+
+	modes := []string{"simulate", "force_simulate", "execute", "force_execute", "skip"}
+	for action, mode := range v {
+		if !stringInSlice(mode.(string), modes) {
+			errors = append(errors, fmt.Errorf("expected %s.%s to be one of %s, got %s", k, action, strings.Join(modes, ", "), mode))
+		}
+	}
+
+	return warnings, errors
+}
+
+// validateTrue permit to check the boolean is true, when setting it to false has no meaning
+func validateTrue(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(bool)
+	if !ok {
+		errors = append(errors, fmt.Errorf("expected type of %s to be bool", k))
+		return warnings, errors
+	}
+
+	if !v {
+		errors = append(errors, fmt.Errorf("expected %s to be true, remove it instead of setting it to false", k))
+	}
+
+	return warnings, errors
+}
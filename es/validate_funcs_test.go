@@ -0,0 +1,69 @@
+package es
+
+import (
+	"testing"
+)
+
+func TestValidateElasticsearchDuration(t *testing.T) {
+	cases := map[string]bool{
+		"30d":     true,
+		"12h":     true,
+		"500ms":   true,
+		"10nanos": true,
+		"":        false,
+		"7":       false,
+		"7 days":  false,
+		"d7":      false,
+	}
+
+	for value, isValid := range cases {
+		_, errs := validateElasticsearchDuration(value, "test")
+		if isValid && len(errs) > 0 {
+			t.Errorf("%q must be valid: %v", value, errs)
+		}
+		if !isValid && len(errs) == 0 {
+			t.Errorf("%q must be invalid", value)
+		}
+	}
+}
+
+func TestValidateElasticsearchCron(t *testing.T) {
+	cases := map[string]bool{
+		"0 30 1 * * ?":        true,
+		"0 0/1 * * * ?":       true,
+		"0 0 12 ? * MON-FRI":  true,
+		"0 15 10 ? * 6L 2025": true,
+		"0 0 12 ? * 2#1":      true,
+		"* * * * *":           false,
+		"0 30 1 * * ? 2025 1": false,
+		"0 30 1 * * $":        false,
+	}
+
+	for value, isValid := range cases {
+		_, errs := validateElasticsearchCron(value, "test")
+		if isValid && len(errs) > 0 {
+			t.Errorf("%q must be valid: %v", value, errs)
+		}
+		if !isValid && len(errs) == 0 {
+			t.Errorf("%q must be invalid", value)
+		}
+	}
+}
+
+func TestValidateTrue(t *testing.T) {
+	cases := map[interface{}]bool{
+		true:   true,
+		false:  false,
+		"true": false,
+	}
+
+	for value, isValid := range cases {
+		_, errs := validateTrue(value, "test")
+		if isValid && len(errs) > 0 {
+			t.Errorf("%v must be valid: %v", value, errs)
+		}
+		if !isValid && len(errs) == 0 {
+			t.Errorf("%v must be invalid", value)
+		}
+	}
+}
@@ -9,41 +9,44 @@ terraform {
   }
 }
 
-provider "elasticsearch" {}
+provider "elasticsearch" {
+  # Fail on create if an object with the same name already exist, instead of overwrite it
+  create_only = true
+}
+
+resource "elasticsearch_composable_index_template" "abc" {
+  name           = "terraform-data-stream-test"
+  index_patterns = ["gaurav"]
+  priority       = 20
 
-resource "elasticsearch_xpack_data_stream_template" "abc" {
-  name         = "terraform-data-stream-test"
-  template     = <<EOF
+  data_stream {}
+
+  template {
+    settings = <<EOF
 {
-  "index_patterns": [
-    "gaurav"
-  ],
-  "data_stream": {},
-  "template": {
-    "settings": {
-      "index.lifecycle.name": "my-data-stream-policy"
-    },
-    "mappings": {
-        "dynamic_templates": [
-            {
-              "integers": {
-                "match_mapping_type": "long",
-                "mapping": {
-                  "type": "integer"
-                }
-              }
-            }
-        ],
-        "properties": {
-          "name" : {
-            "type": "keyword"
-          }
+  "index.lifecycle.name": "my-data-stream-policy"
+}
+EOF
+    mappings = <<EOF
+{
+  "dynamic_templates": [
+    {
+      "integers": {
+        "match_mapping_type": "long",
+        "mapping": {
+          "type": "integer"
         }
+      }
     }
-  },
-  "priority": 20
+  ],
+  "properties": {
+    "name" : {
+      "type": "keyword"
+    }
+  }
 }
 EOF
+  }
 }
 
 resource "elasticsearch_index_template" "test" {